@@ -4,14 +4,26 @@ import (
 	"context"
 	"fmt"
 	_ "go-sosmed/docs"
+	"go-sosmed/internal/account"
+	"go-sosmed/internal/block"
 	"go-sosmed/internal/comment"
+	"go-sosmed/internal/export"
+	"go-sosmed/internal/feed"
 	"go-sosmed/internal/follow"
 	"go-sosmed/internal/like"
+	"go-sosmed/internal/mute"
 	"go-sosmed/internal/post"
 	"go-sosmed/internal/report"
+	"go-sosmed/internal/setting"
+	"go-sosmed/internal/timeline"
+	"go-sosmed/internal/token"
 	"go-sosmed/internal/user"
+	"go-sosmed/internal/verification"
 	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/jwtkeys"
+	"go-sosmed/pkg/mailer"
 	"go-sosmed/pkg/middlewares"
+	"go-sosmed/pkg/recount"
 	"log"
 	"time"
 
@@ -38,7 +50,7 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
-// @description Type "Bearer" followed by a space and JWT token.
+// @description Type "Bearer" followed by a space and a JWT or personal access token.
 
 func main() {
 	cfg := config.LoadConfig()
@@ -71,17 +83,64 @@ func main() {
 	db := config.GetDB()
 	tables := []interface{}{
 		&user.User{},
+		&user.PasswordResetToken{},
+		&user.RecoveryCode{},
+		&user.UserIdentity{},
+		&user.LoginThrottle{},
+		&user.UserSuspension{},
+		&user.UsernameHistory{},
 		&post.Post{},
 		&like.Like{},
 		&follow.Follow{},
+		&block.Block{},
+		&mute.Mute{},
+		&timeline.TimelineEntry{},
+		&timeline.TimelineLargeAccount{},
+		&verification.VerificationRequest{},
+		&setting.UserSettings{},
 		&comment.Comment{},
 		&report.Report{},
+		&token.Session{},
+		&token.RefreshToken{},
+		&token.RevokedToken{},
+		&token.PersonalAccessToken{},
+		&jwtkeys.SigningKey{},
+		&export.DataExport{},
 	}
+	// akun yang sudah ada sebelum kolom email_verified_at dibuat dianggap terverifikasi
+	backfillEmailVerified := db.Migrator().HasTable(&user.User{}) &&
+		!db.Migrator().HasColumn(&user.User{}, "EmailVerifiedAt")
+	// counter yang baru dibuat bernilai 0, diisi dari tabel sumbernya
+	recountPosts := db.Migrator().HasTable(&post.Post{}) &&
+		!(db.Migrator().HasColumn(&post.Post{}, "LikeCount") && db.Migrator().HasColumn(&post.Post{}, "CommentCount"))
+	recountUsers := db.Migrator().HasTable(&user.User{}) &&
+		!(db.Migrator().HasColumn(&user.User{}, "FollowersCount") && db.Migrator().HasColumn(&user.User{}, "FollowingCount"))
 	if err := db.AutoMigrate(tables...); err != nil {
 		log.Fatalf("Database migration failed: %v", err)
 	}
+	if backfillEmailVerified {
+		if err := db.Model(&user.User{}).Where("email_verified_at IS NULL").
+			Update("email_verified_at", time.Now()).Error; err != nil {
+			log.Fatalf("Failed to mark existing users as verified: %v", err)
+		}
+	}
+	if recountPosts {
+		if _, err := recount.All(db, "posts", post.RecountCounters); err != nil {
+			log.Fatalf("Failed to fill post counters: %v", err)
+		}
+	}
+	if recountUsers {
+		if _, err := recount.All(db, "users", user.RecountFollowCounts); err != nil {
+			log.Fatalf("Failed to fill follow counters: %v", err)
+		}
+	}
 	log.Println("✅ Migrasi database berhasil.")
 
+	// === JWT Signing Keys ===
+	if err := jwtkeys.Init(db, cfg); err != nil {
+		log.Fatalf("Failed to initialize JWT signing keys: %v", err)
+	}
+
 	// === Home Route ===
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -116,14 +175,25 @@ func main() {
 	r.Use(middlewares.GinErrorHandler())
 
 	//seeder
-	user.SeedAdminUser()
+	user.SeedAdminUser(cfg)
+	tokenRepo := token.NewRepository(db)
+	tokenService := token.NewService(tokenRepo, cfg)
+	tokenController := token.NewController(tokenService)
+	token.SetupRoute(r, tokenController, cfg)
+
 	userRepo := user.NewRepository(db)
-	userService := user.NewService(userRepo, cfg)
+	mail := mailer.New(cfg)
+	userService := user.NewService(userRepo, tokenService, mail, cfg)
 	userController := user.NewController(userService, cfg)
 	user.SetupRoute(r, userController, cfg)
 
+	settingRepo := setting.NewRepository(db)
+	settingService := setting.NewService(settingRepo)
+	settingController := setting.NewController(settingService)
+	setting.SetupRoute(r, settingController, cfg)
+
 	postRepo := post.NewRepository(db)
-	postService := post.NewService(postRepo)
+	postService := post.NewService(postRepo, settingService)
 	postController := post.NewController(postService)
 	post.SetupPostRoute(r, postController, cfg)
 
@@ -137,16 +207,49 @@ func main() {
 	followController := follow.NewController(followService)
 	follow.SetupFollowRoute(r, followController, cfg)
 
+	blockRepo := block.NewRepository(db)
+	blockService := block.NewService(blockRepo)
+	blockController := block.NewController(blockService)
+	block.SetupRoute(r, blockController, cfg)
+
+	muteRepo := mute.NewRepository(db)
+	muteService := mute.NewService(muteRepo)
+	muteController := mute.NewController(muteService)
+	mute.SetupRoute(r, muteController, cfg)
+
 	commentRepo := comment.NewRepository(db)
-	commentService := comment.NewService(commentRepo, postRepo)
+	commentService := comment.NewService(commentRepo, postRepo, settingService)
 	commentController := comment.NewController(commentService)
 	comment.SetupCommentRoute(r, commentController, cfg)
 
+	feedRepo := feed.NewRepository(db)
+	feedService := feed.NewService(feedRepo, nil)
+	feedController := feed.NewController(feedService)
+	feed.SetupRoute(r, feedController, cfg)
+
 	reportRepo := report.NewRepository(db)
 	reportService := report.NewService(reportRepo)
 	reportController := report.NewController(reportService)
 	report.SetupRoute(r, reportController, cfg)
 
+	verificationRepo := verification.NewRepository(db)
+	verificationService := verification.NewService(verificationRepo, mail)
+	verificationController := verification.NewController(verificationService)
+	verification.SetupRoute(r, verificationController, cfg)
+
+	exportRepo := export.NewRepository(db)
+	exportService := export.NewService(exportRepo, userRepo, postRepo, commentRepo, likeRepo, followRepo, reportRepo, mail, cfg)
+	exportController := export.NewController(exportService)
+	export.SetupRoute(r, exportController, cfg)
+
+	// === Background Jobs ===
+	accountRepo := account.NewRepository(db)
+	accountService := account.NewService(accountRepo, cfg)
+	accountService.StartPurgeWorker()
+	exportService.StartWorker()
+	postService.StartScheduler()
+	timeline.StartBackfill(db)
+
 	// 404 Not Found
 	r.NoRoute(func(c *gin.Context) {
 		c.JSON(404, gin.H{"error": "Route not found"})
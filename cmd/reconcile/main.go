@@ -0,0 +1,40 @@
+// Command reconcile menghitung ulang counter like, komentar dan follow dari
+// tabel sumbernya. Counter dijaga secara transaksional oleh aplikasi dan diisi
+// otomatis oleh server saat kolomnya pertama kali dibuat; command ini dipakai
+// jika ada selisih akibat perubahan data langsung di database.
+//
+//	go run ./cmd/reconcile
+package main
+
+import (
+	"go-sosmed/internal/post"
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/recount"
+	"log"
+)
+
+func main() {
+	cfg := config.LoadConfig()
+	if err := config.Connect(cfg); err != nil {
+		log.Fatalf("Unable to connect to database: %v", err)
+	}
+	db := config.GetDB()
+
+	// kolom counter dibuat oleh AutoMigrate
+	if err := db.AutoMigrate(&user.User{}, &post.Post{}); err != nil {
+		log.Fatalf("Database migration failed: %v", err)
+	}
+
+	posts, err := recount.All(db, "posts", post.RecountCounters)
+	if err != nil {
+		log.Fatalf("Failed to reconcile post counters: %v", err)
+	}
+	log.Printf("Post counters reconciled, %d posts corrected", posts)
+
+	users, err := recount.All(db, "users", user.RecountFollowCounts)
+	if err != nil {
+		log.Fatalf("Failed to reconcile follow counters: %v", err)
+	}
+	log.Printf("Follow counters reconciled, %d users corrected", users)
+}
@@ -0,0 +1,8 @@
+package account
+
+// PurgeResult merangkum data yang dihapus untuk satu akun
+type PurgeResult struct {
+	Posts    int
+	Comments int
+	Files    []string // file upload (avatar, banner, gambar post) dan arsip export yang harus dihapus setelah commit
+}
@@ -0,0 +1,209 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"go-sosmed/internal/block"
+	"go-sosmed/internal/comment"
+	"go-sosmed/internal/export"
+	"go-sosmed/internal/follow"
+	"go-sosmed/internal/like"
+	"go-sosmed/internal/mute"
+	"go-sosmed/internal/post"
+	"go-sosmed/internal/report"
+	"go-sosmed/internal/setting"
+	"go-sosmed/internal/timeline"
+	"go-sosmed/internal/token"
+	"go-sosmed/internal/user"
+	"go-sosmed/internal/verification"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	FindDueForPurge(now time.Time, limit int) ([]*user.User, error)
+	Purge(userID uint, now time.Time) (*PurgeResult, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// FindDueForPurge implements Repository.
+func (r *repository) FindDueForPurge(now time.Time, limit int) ([]*user.User, error) {
+	var users []*user.User
+	err := r.db.
+		Scopes(dueForPurge(now)).
+		Order("deletion_scheduled_at ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// dueForPurge memastikan jadwal penghapusan masih berlaku saat purge berjalan,
+// karena user bisa login (membatalkan penghapusan) setelah FindDueForPurge
+func dueForPurge(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ? AND purged_at IS NULL", now)
+	}
+}
+
+// Purge implements Repository.
+// Semua konten user dihapus dan data pribadinya dianonimkan dalam satu transaksi.
+// Baris user tetap ada (dengan username/email anonim) agar ID tidak dipakai ulang.
+// File upload tidak dihapus di sini, path-nya dikembalikan untuk dihapus setelah commit.
+// Baris user dikunci dan jadwalnya dicek ulang; ErrNotDue jika penghapusan sudah dibatalkan.
+func (r *repository) Purge(userID uint, now time.Time) (*PurgeResult, error) {
+	result := &PurgeResult{}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var u user.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(dueForPurge(now)).
+			Where("id = ?", userID).
+			First(&u).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotDue
+		}
+		if err != nil {
+			return err
+		}
+		if u.Avatar != "" {
+			result.Files = append(result.Files, u.Avatar)
+		}
+		if u.Banner != "" {
+			result.Files = append(result.Files, u.Banner)
+		}
+
+		var posts []post.Post
+		if err := tx.Unscoped().Select("id, image").Where("author_id = ?", u.ID).Find(&posts).Error; err != nil {
+			return err
+		}
+		postIDs := []uint{0} // placeholder agar klausa IN tidak kosong
+		for _, p := range posts {
+			postIDs = append(postIDs, p.ID)
+			if p.Image != "" {
+				result.Files = append(result.Files, p.Image)
+			}
+		}
+		result.Posts = len(posts)
+
+		var exports []export.DataExport
+		if err := tx.Select("id, file_path").Where("user_id = ? AND file_path <> ''", u.ID).Find(&exports).Error; err != nil {
+			return err
+		}
+		for _, e := range exports {
+			result.Files = append(result.Files, e.FilePath)
+		}
+
+		// like, komentar dan follow user ini ikut terhapus; counter pihak lain
+		// dihitung ulang setelahnya
+		var touchedPosts, touchedUsers []uint
+		err = tx.Raw(`
+			SELECT post_id FROM likes WHERE user_id = ?
+			UNION SELECT post_id FROM comments WHERE user_id = ?
+		`, u.ID, u.ID).Scan(&touchedPosts).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Raw(`
+			SELECT following_id FROM follows WHERE follower_id = ?
+			UNION SELECT follower_id FROM follows WHERE following_id = ?
+		`, u.ID, u.ID).Scan(&touchedUsers).Error
+		if err != nil {
+			return err
+		}
+		touchedUsers = append(touchedUsers, u.ID)
+
+		steps := []struct {
+			model interface{}
+			query string
+			args  []interface{}
+		}{
+			{&like.Like{}, "user_id = ? OR post_id IN ?", []interface{}{u.ID, postIDs}},
+			{&report.Report{}, "user_id = ? OR post_id IN ?", []interface{}{u.ID, postIDs}},
+			{&follow.Follow{}, "follower_id = ? OR following_id = ?", []interface{}{u.ID, u.ID}},
+			{&block.Block{}, "blocker_id = ? OR blocked_id = ?", []interface{}{u.ID, u.ID}},
+			{&mute.Mute{}, "user_id = ? OR muted_user_id = ?", []interface{}{u.ID, u.ID}},
+			{&timeline.TimelineEntry{}, "user_id = ? OR author_id = ?", []interface{}{u.ID, u.ID}},
+			{&timeline.TimelineLargeAccount{}, "user_id = ?", []interface{}{u.ID}},
+			{&token.Session{}, "user_id = ?", []interface{}{u.ID}},
+			{&token.RefreshToken{}, "user_id = ?", []interface{}{u.ID}},
+			{&token.PersonalAccessToken{}, "user_id = ?", []interface{}{u.ID}},
+			{&user.UserIdentity{}, "user_id = ?", []interface{}{u.ID}},
+			{&user.RecoveryCode{}, "user_id = ?", []interface{}{u.ID}},
+			{&user.PasswordResetToken{}, "user_id = ?", []interface{}{u.ID}},
+			{&user.UsernameHistory{}, "user_id = ?", []interface{}{u.ID}},
+			{&verification.VerificationRequest{}, "user_id = ?", []interface{}{u.ID}},
+			{&setting.UserSettings{}, "user_id = ?", []interface{}{u.ID}},
+			{&export.DataExport{}, "user_id = ?", []interface{}{u.ID}},
+		}
+		for _, step := range steps {
+			if err := tx.Where(step.query, step.args...).Delete(step.model).Error; err != nil {
+				return fmt.Errorf("failed to delete %T: %w", step.model, err)
+			}
+		}
+
+		// reply ke user ini tetap ada, hanya penanda "membalas" yang dilepas
+		if err := tx.Model(&comment.Comment{}).
+			Where("reply_to_user_id = ?", u.ID).
+			Update("reply_to_user_id", nil).Error; err != nil {
+			return err
+		}
+		// balasan ikut terhapus lewat ON DELETE CASCADE pada parent_id
+		deleted := tx.Where("user_id = ? OR post_id IN ?", u.ID, postIDs).Delete(&comment.Comment{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		result.Comments = int(deleted.RowsAffected)
+
+		if err := tx.Unscoped().Where("author_id = ?", u.ID).Delete(&post.Post{}).Error; err != nil {
+			return err
+		}
+
+		if _, err := post.RecountCounters(tx, touchedPosts); err != nil {
+			return err
+		}
+		if _, err := user.RecountFollowCounts(tx, touchedUsers); err != nil {
+			return err
+		}
+
+		updated := tx.Model(&user.User{}).Scopes(dueForPurge(now)).Where("id = ?", u.ID).Updates(map[string]interface{}{
+			"username":              fmt.Sprintf("deleted_user_%d", u.ID),
+			"email":                 fmt.Sprintf("deleted_%d@deleted.invalid", u.ID),
+			"password":              "",
+			"bio":                   "",
+			"avatar":                "",
+			"banner":                "",
+			"display_name":          "",
+			"location":              "",
+			"links":                 nil,
+			"birthday":              nil,
+			"totp_secret":           "",
+			"two_factor_enabled":    false,
+			"email_verified_at":     nil,
+			"deletion_scheduled_at": nil,
+			"username_changed_at":   nil,
+			"verified_at":           nil,
+			"tokens_revoked_at":     now,
+			"purged_at":             now,
+		})
+		if updated.Error != nil {
+			return updated.Error
+		}
+		if updated.RowsAffected == 0 {
+			return ErrNotDue
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,81 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/utils"
+	"log"
+	"time"
+)
+
+// ErrNotDue dikembalikan Purge jika akun tidak lagi dijadwalkan untuk dihapus
+var ErrNotDue = errors.New("account is no longer due for purge")
+
+// jumlah akun maksimal yang diproses per putaran job
+const purgeBatchSize = 50
+
+type Service interface {
+	PurgeDueAccounts() (int, error)
+	StartPurgeWorker()
+}
+
+type service struct {
+	repo Repository
+	cfg  *config.Config
+}
+
+// PurgeDueAccounts implements Service.
+// Menghapus akun yang masa tenggang penghapusannya sudah lewat.
+func (s *service) PurgeDueAccounts() (int, error) {
+	now := time.Now()
+	users, err := s.repo.FindDueForPurge(now, purgeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find accounts to purge: %w", err)
+	}
+
+	purged := 0
+	for _, u := range users {
+		result, err := s.repo.Purge(u.ID, now)
+		if errors.Is(err, ErrNotDue) {
+			log.Printf("Account %d no longer due for purge, skipped", u.ID)
+			continue
+		}
+		if err != nil {
+			log.Printf("Warning: failed to purge account %d: %v", u.ID, err)
+			continue
+		}
+		for _, file := range result.Files {
+			if err := utils.DeleteFile(file); err != nil {
+				log.Printf("Warning: failed to delete file of purged account %d: %v", u.ID, err)
+			}
+		}
+		log.Printf("Account %d purged (%d posts, %d comments, %d files)", u.ID, result.Posts, result.Comments, len(result.Files))
+		purged++
+	}
+	return purged, nil
+}
+
+// StartPurgeWorker implements Service.
+// Menjalankan PurgeDueAccounts secara berkala di background.
+func (s *service) StartPurgeWorker() {
+	interval, err := time.ParseDuration(s.cfg.AccountPurgeInterval)
+	if err != nil || interval <= 0 {
+		interval = time.Hour
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.PurgeDueAccounts(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+func NewService(repo Repository, cfg *config.Config) Service {
+	return &service{repo: repo, cfg: cfg}
+}
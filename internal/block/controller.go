@@ -0,0 +1,151 @@
+package block
+
+import (
+	"errors"
+	"go-sosmed/pkg/pagination"
+	"go-sosmed/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service Service
+}
+
+// helper function to get userID from context
+func GetUserIDFromContext(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	uid, ok := userID.(uint)
+	return uid, ok
+}
+
+func ParseUserID(c *gin.Context) (uint, error) {
+	idParam := c.Param("user_id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// BlockUser godoc
+// @Summary Block a user
+// @Description Block a user. Existing follows in both directions are removed and the two users can no longer follow, like, comment on or reply to each other.
+// @Tags Block
+// @Produce json
+// @Param user_id path int true "User ID to block"
+// @Security BearerAuth
+// @Success 201 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/users/{user_id}/block [post]
+func (ctrl *Controller) BlockUser(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	blockedID, err := ParseUserID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := ctrl.service.BlockUser(userID, blockedID); err != nil {
+		switch {
+		case errors.Is(err, ErrCannotBlockSelf):
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrUserNotFound):
+			response.Error(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrAlreadyBlocked):
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "user blocked successfully", nil)
+}
+
+// UnblockUser godoc
+// @Summary Unblock a user
+// @Description Remove a block. Follows removed by the block are not restored.
+// @Tags Block
+// @Produce json
+// @Param user_id path int true "User ID to unblock"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/users/{user_id}/block [delete]
+func (ctrl *Controller) UnblockUser(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	blockedID, err := ParseUserID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := ctrl.service.UnblockUser(userID, blockedID); err != nil {
+		if errors.Is(err, ErrNotBlocked) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "user unblocked successfully", nil)
+}
+
+// GetBlockedUsers godoc
+// @Summary List blocked users
+// @Description List the users blocked by the authenticated user
+// @Tags Block
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Security BearerAuth
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/blocks [get]
+func (ctrl *Controller) GetBlockedUsers(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	blocks, next, err := ctrl.service.GetBlockedUsers(userID, page)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Paginated(c, http.StatusOK, "blocked users retrieved successfully", blocks, next)
+}
+
+func NewController(service Service) *Controller {
+	return &Controller{service: service}
+}
@@ -0,0 +1,16 @@
+package block
+
+import "go-sosmed/internal/user"
+
+func ToBlockResponse(b *Block) *BlockResponse {
+	return &BlockResponse{
+		ID: b.ID,
+		User: user.AuthorResponse{
+			ID:         b.Blocked.ID,
+			Username:   b.Blocked.Username,
+			Avatar:     b.Blocked.Avatar,
+			IsVerified: b.Blocked.VerifiedAt != nil,
+		},
+		CreatedAt: b.CreatedAt,
+	}
+}
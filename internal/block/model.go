@@ -0,0 +1,22 @@
+package block
+
+import (
+	"go-sosmed/internal/user"
+	"time"
+)
+
+// Block mencegah interaksi dua arah antara BlockerID dan BlockedID
+type Block struct {
+	ID        uint      `gorm:"primaryKey"`
+	BlockerID uint      `gorm:"not null;uniqueIndex:idx_blocker_blocked"`
+	BlockedID uint      `gorm:"not null;uniqueIndex:idx_blocker_blocked;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	// Relations
+	Blocked user.User `gorm:"foreignKey:BlockedID"`
+}
+
+type BlockResponse struct {
+	ID        uint                `json:"id"`
+	User      user.AuthorResponse `json:"user"`
+	CreatedAt time.Time           `json:"created_at"`
+}
@@ -0,0 +1,98 @@
+package block
+
+import (
+	"go-sosmed/internal/follow"
+	"go-sosmed/internal/timeline"
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(block *Block) error
+	Delete(id uint) error
+	FindByPair(blockerID, blockedID uint) (*Block, error)
+	FindByBlocker(blockerID uint, page pagination.Page) ([]*Block, error)
+	UserExists(userID uint) (bool, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// Create implements Repository.
+// Follow (dan follow request) di kedua arah ikut dihapus dalam transaksi yang sama,
+// begitu juga post masing-masing di timeline pihak lain dan counter follow.
+func (r *repository) Create(block *Block) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(block).Error; err != nil {
+			return err
+		}
+		var follows []follow.Follow
+		err := tx.
+			Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)",
+				block.BlockerID, block.BlockedID, block.BlockedID, block.BlockerID).
+			Find(&follows).Error
+		if err != nil {
+			return err
+		}
+		for _, f := range follows {
+			if err := tx.Delete(&follow.Follow{}, f.ID).Error; err != nil {
+				return err
+			}
+			if f.Status != follow.StatusAccepted {
+				continue
+			}
+			if err := user.AdjustFollowCounts(tx, f.FollowerID, f.FollowingID, -1); err != nil {
+				return err
+			}
+		}
+		if err := timeline.Prune(tx, block.BlockerID, block.BlockedID); err != nil {
+			return err
+		}
+		return timeline.Prune(tx, block.BlockedID, block.BlockerID)
+	})
+}
+
+// Delete implements Repository.
+func (r *repository) Delete(id uint) error {
+	return r.db.Delete(&Block{}, id).Error
+}
+
+// FindByPair implements Repository.
+func (r *repository) FindByPair(blockerID, blockedID uint) (*Block, error) {
+	var block Block
+	err := r.db.
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		First(&block).Error
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// FindByBlocker implements Repository.
+func (r *repository) FindByBlocker(blockerID uint, page pagination.Page) ([]*Block, error) {
+	var blocks []*Block
+	err := r.db.
+		Preload("Blocked").
+		Where("blocker_id = ?", blockerID).
+		Scopes(page.Newest("blocks.created_at", "blocks.id")).
+		Find(&blocks).Error
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// UserExists implements Repository.
+func (r *repository) UserExists(userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&user.User{}).Where("id = ? AND purged_at IS NULL", userID).Count(&count).Error
+	return count > 0, err
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,17 @@
+package block
+
+import (
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
+	api := r.Group("/api/users")
+	api.Use(middlewares.Authenticate(cfg))
+
+	api.GET("/me/blocks", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetBlockedUsers)
+	api.POST("/:user_id/block", middlewares.RequireScope(middlewares.ScopeBlocksWrite), ctrl.BlockUser)
+	api.DELETE("/:user_id/block", middlewares.RequireScope(middlewares.ScopeBlocksWrite), ctrl.UnblockUser)
+}
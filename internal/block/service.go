@@ -0,0 +1,87 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+	"go-sosmed/pkg/pagination"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCannotBlockSelf = errors.New("cannot block yourself")
+	ErrAlreadyBlocked  = errors.New("user already blocked")
+	ErrNotBlocked      = errors.New("user is not blocked")
+	ErrUserNotFound    = errors.New("user not found")
+)
+
+type Service interface {
+	BlockUser(blockerID, blockedID uint) error
+	UnblockUser(blockerID, blockedID uint) error
+	GetBlockedUsers(blockerID uint, page pagination.Page) ([]*BlockResponse, string, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// BlockUser implements Service.
+func (s *service) BlockUser(blockerID uint, blockedID uint) error {
+	if blockerID == blockedID {
+		return ErrCannotBlockSelf
+	}
+
+	exists, err := s.repo.UserExists(blockedID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	existing, err := s.repo.FindByPair(blockerID, blockedID)
+	if err == nil && existing != nil {
+		return ErrAlreadyBlocked
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err := s.repo.Create(&Block{BlockerID: blockerID, BlockedID: blockedID}); err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+	return nil
+}
+
+// UnblockUser implements Service.
+// Follow yang terhapus saat block tidak dikembalikan.
+func (s *service) UnblockUser(blockerID uint, blockedID uint) error {
+	existing, err := s.repo.FindByPair(blockerID, blockedID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotBlocked
+		}
+		return err
+	}
+	return s.repo.Delete(existing.ID)
+}
+
+// GetBlockedUsers implements Service.
+func (s *service) GetBlockedUsers(blockerID uint, page pagination.Page) ([]*BlockResponse, string, error) {
+	blocks, err := s.repo.FindByBlocker(blockerID, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get blocked users: %w", err)
+	}
+	blocks, next := pagination.Trim(blocks, page, func(b *Block) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: b.CreatedAt, ID: b.ID}
+	})
+	responses := make([]*BlockResponse, 0, len(blocks))
+	for _, b := range blocks {
+		responses = append(responses, ToBlockResponse(b))
+	}
+	return responses, next, nil
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
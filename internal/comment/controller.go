@@ -1,6 +1,8 @@
 package comment
 
 import (
+	"errors"
+	"go-sosmed/pkg/pagination"
 	"go-sosmed/pkg/response"
 	"strconv"
 
@@ -53,6 +55,7 @@ func ParseCommentID(c *gin.Context) (uint, error) {
 // @Success 201 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Router /api/posts/{post_id}/comments [post]
 func (ctrl *Controller) CreateComment(c *gin.Context) {
 	var req CommentRequest
@@ -81,6 +84,10 @@ func (ctrl *Controller) CreateComment(c *gin.Context) {
 
 	saved, err := ctrl.service.CreateComment(comment)
 	if err != nil {
+		if errors.Is(err, ErrBlocked) || errors.Is(err, ErrCommentsRestricted) {
+			response.Error(c, 403, err.Error())
+			return
+		}
 		response.Error(c, 400, err.Error())
 		return
 	}
@@ -101,6 +108,7 @@ func (ctrl *Controller) CreateComment(c *gin.Context) {
 // @Success 201 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Router /api/posts/{post_id}/comments/{comment_id}/reply [post]
 func (ctrl *Controller) ReplyToComment(c *gin.Context) {
@@ -153,6 +161,10 @@ func (ctrl *Controller) ReplyToComment(c *gin.Context) {
 	// Save reply
 	saved, err := ctrl.service.CreateComment(reply)
 	if err != nil {
+		if errors.Is(err, ErrBlocked) || errors.Is(err, ErrCommentsRestricted) {
+			response.Error(c, 403, err.Error())
+			return
+		}
 		response.Error(c, 500, err.Error())
 		return
 	}
@@ -229,7 +241,9 @@ func (ctrl *Controller) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	err = ctrl.service.DeleteComment(userID, uint(commentID))
+	userRole := c.GetString("userRole")
+
+	err = ctrl.service.DeleteComment(userID, userRole, uint(commentID))
 	if err != nil {
 		response.Error(c, 400, err.Error())
 		return
@@ -245,8 +259,10 @@ func (ctrl *Controller) DeleteComment(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param post_id path int true "Post ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Security BearerAuth
-// @Success 200 {object} response.SuccessResponse
+// @Success 200 {object} response.PaginatedResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Router /api/posts/{post_id}/comments [get]
@@ -257,19 +273,26 @@ func (ctrl *Controller) GetCommentTree(c *gin.Context) {
 		return
 	}
 
-	tree, err := ctrl.service.GetCommentTree(uint(postID))
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	userID, _ := GetUserIDFromContext(c)
+	tree, next, err := ctrl.service.GetCommentTree(uint(postID), userID, page)
 	if err != nil {
 		response.Error(c, 400, err.Error())
 		return
 	}
 
 	// MAP model -> DTO
-	var resp []CommentResponse
+	resp := make([]CommentResponse, 0, len(tree))
 	for i := range tree {
 		resp = append(resp, ToCommentResponse(&tree[i]))
 	}
 
-	response.Success(c, 200, "comments retrieved successfully", resp)
+	response.Paginated(c, 200, "comments retrieved successfully", resp, next)
 }
 
 // GetReplies godoc
@@ -280,8 +303,10 @@ func (ctrl *Controller) GetCommentTree(c *gin.Context) {
 // @Produce json
 // @Param post_id path int true "Post ID"
 // @Param comment_id path int true "Comment ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Security BearerAuth
-// @Success 200 {object} response.SuccessResponse
+// @Success 200 {object} response.PaginatedResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Router /api/posts/{post_id}/comments/{comment_id}/replies [get]
@@ -292,18 +317,25 @@ func (ctrl *Controller) GetReplies(c *gin.Context) {
 		return
 	}
 
-	replies, err := ctrl.service.GetReplies(uint(commentID))
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	userID, _ := GetUserIDFromContext(c)
+	replies, next, err := ctrl.service.GetReplies(uint(commentID), userID, page)
 	if err != nil {
 		response.Error(c, 400, err.Error())
 		return
 	}
 
-	var resp []CommentResponse
+	resp := make([]CommentResponse, 0, len(replies))
 	for _, reply := range replies {
 		resp = append(resp, ToCommentResponse(&reply))
 	}
 
-	response.Success(c, 200, "replies retrieved successfully", resp)
+	response.Paginated(c, 200, "replies retrieved successfully", resp, next)
 }
 
 func NewController(service Service) *Controller {
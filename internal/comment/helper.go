@@ -1,6 +1,9 @@
 package comment
 
-import "go-sosmed/internal/user"
+import (
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
+)
 
 func ToCommentResponse(c *Comment) CommentResponse {
 	resp := CommentResponse{
@@ -9,16 +12,18 @@ func ToCommentResponse(c *Comment) CommentResponse {
 		CreatedAt: c.CreatedAt,
 		Edited:    c.Edited,
 		User: user.AuthorResponse{
-			ID:       c.User.ID,
-			Username: c.User.Username,
-			Avatar:   c.User.Avatar,
+			ID:         c.User.ID,
+			Username:   c.User.Username,
+			Avatar:     c.User.Avatar,
+			IsVerified: c.User.VerifiedAt != nil,
 		},
 	}
 
 	if c.ReplyToUser != nil {
 		resp.ReplyToUser = &user.AuthorResponse{
-			ID:       c.ReplyToUser.ID,
-			Username: c.ReplyToUser.Username,
+			ID:         c.ReplyToUser.ID,
+			Username:   c.ReplyToUser.Username,
+			IsVerified: c.ReplyToUser.VerifiedAt != nil,
 		}
 	}
 
@@ -28,3 +33,7 @@ func ToCommentResponse(c *Comment) CommentResponse {
 
 	return resp
 }
+
+func commentCursor(c Comment) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+}
@@ -1,6 +1,13 @@
 package comment
 
-import "gorm.io/gorm"
+import (
+	"go-sosmed/internal/mute"
+	"go-sosmed/internal/post"
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
+
+	"gorm.io/gorm"
+)
 
 type Repository interface {
 	//main
@@ -10,10 +17,13 @@ type Repository interface {
 	Update(comment *Comment) error
 	Delete(comment *Comment) error
 	//replies
-	GetReplies(parentID uint) ([]Comment, error)
+	GetReplies(parentID, viewerID uint, page pagination.Page) ([]Comment, error)
 	//utils
 	IsOwner(commentID uint, userID uint) (bool, error)
-	GetCommentTree(postID uint) ([]Comment, error)
+	GetCommentTree(postID, viewerID uint, page pagination.Page) ([]Comment, error)
+	FindByUserID(userID uint) ([]Comment, error)
+	IsBlocked(userA, userB uint) (bool, error)
+	IsFollowing(followerID, followingID uint) (bool, error)
 }
 
 type repository struct {
@@ -21,13 +31,32 @@ type repository struct {
 }
 
 // Create implements Repository.
+// comment_count post ikut dinaikkan dalam transaksi yang sama.
 func (r *repository) Create(comment *Comment) error {
-	return r.db.Create(comment).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		return post.AdjustCommentCount(tx, comment.PostID, 1)
+	})
 }
 
 // Delete implements Repository.
+// Balasan ikut terhapus lewat ON DELETE CASCADE, jadi comment_count post
+// diturunkan sebanyak komentar beserta balasannya.
 func (r *repository) Delete(comment *Comment) error {
-	return r.db.Delete(comment).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var replies int64
+		if comment.ParentID == nil {
+			if err := tx.Model(&Comment{}).Where("parent_id = ?", comment.ID).Count(&replies).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(comment).Error; err != nil {
+			return err
+		}
+		return post.AdjustCommentCount(tx, comment.PostID, -int(replies+1))
+	})
 }
 
 // GetByID implements Repository.
@@ -53,18 +82,33 @@ func (r *repository) GetByID(id uint) (*Comment, error) {
 	return &c, nil
 }
 
+func notMuted(viewerID uint) func(db *gorm.DB) *gorm.DB {
+	return mute.NotMuted("comments.user_id", []string{"comments.content"}, viewerID)
+}
+
 // GetCommentTree implements Repository.
-func (r *repository) GetCommentTree(postID uint) ([]Comment, error) {
+// Komentar dan reply dari user yang saling memblokir dengan viewer, serta yang
+// di-mute oleh viewer, tidak ditampilkan. Pagination berlaku untuk komentar
+// level atas, urut dari yang terlama.
+func (r *repository) GetCommentTree(postID, viewerID uint, page pagination.Page) ([]Comment, error) {
 	var comments []Comment
 
 	err := r.db.
 		Preload("User").
 		Preload("ReplyToUser").
-		Preload("Replies").
+		Preload("Replies", func(db *gorm.DB) *gorm.DB {
+			return db.Scopes(user.NotBlocked("comments.user_id", viewerID), notMuted(viewerID)).
+				Order("comments.created_at ASC, comments.id ASC")
+		}).
 		Preload("Replies.User").
 		Preload("Replies.ReplyToUser").
 		Where("post_id = ?", postID).
 		Where("parent_id IS NULL").
+		Scopes(
+			user.NotBlocked("comments.user_id", viewerID),
+			notMuted(viewerID),
+			page.Oldest("comments.created_at", "comments.id"),
+		).
 		Find(&comments).Error
 
 	if err != nil {
@@ -74,11 +118,30 @@ func (r *repository) GetCommentTree(postID uint) ([]Comment, error) {
 	return comments, nil
 }
 
+// FindByUserID implements Repository.
+// Semua komentar dan reply yang ditulis user, tanpa relasi.
+func (r *repository) FindByUserID(userID uint) ([]Comment, error) {
+	var comments []Comment
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
 // GetReplies implements Repository.
-func (r *repository) GetReplies(parentID uint) ([]Comment, error) {
+func (r *repository) GetReplies(parentID, viewerID uint, page pagination.Page) ([]Comment, error) {
 	var replies []Comment
 	err := r.db.
 		Where("parent_id = ?", parentID).
+		Scopes(
+			user.NotBlocked("comments.user_id", viewerID),
+			notMuted(viewerID),
+			page.Oldest("comments.created_at", "comments.id"),
+		).
 		Preload("User").
 		Preload("ReplyToUser").
 		Preload("Post").
@@ -105,6 +168,16 @@ func (r *repository) GetRootCommentsByPostID(postID uint) ([]Comment, error) {
 	return comments, nil
 }
 
+// IsBlocked implements Repository.
+func (r *repository) IsBlocked(userA, userB uint) (bool, error) {
+	return user.IsBlockedBetween(r.db, userA, userB)
+}
+
+// IsFollowing implements Repository.
+func (r *repository) IsFollowing(followerID, followingID uint) (bool, error) {
+	return user.IsAcceptedFollower(r.db, followerID, followingID)
+}
+
 // IsOwner implements Repository.
 func (r *repository) IsOwner(commentID uint, userID uint) (bool, error) {
 	var count int64
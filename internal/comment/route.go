@@ -12,14 +12,14 @@ func SetupCommentRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
 	api.Use(middlewares.Authenticate(cfg))
 
 	// Create & get comments of a post
-	api.POST("/posts/:post_id/comments", ctrl.CreateComment)
-	api.GET("/posts/:post_id/comments", ctrl.GetCommentTree)
+	api.POST("/posts/:post_id/comments", middlewares.RequireScope(middlewares.ScopeCommentsWrite), middlewares.RequireVerifiedEmail(), ctrl.CreateComment)
+	api.GET("/posts/:post_id/comments", middlewares.RequireScope(middlewares.ScopeCommentsRead), ctrl.GetCommentTree)
 
 	// Replies (level 2 rule)
-	api.POST("/posts/:post_id/comments/:comment_id/reply", ctrl.ReplyToComment)
-	api.GET("/posts/:post_id/comments/:comment_id/replies", ctrl.GetReplies)
+	api.POST("/posts/:post_id/comments/:comment_id/reply", middlewares.RequireScope(middlewares.ScopeCommentsWrite), middlewares.RequireVerifiedEmail(), ctrl.ReplyToComment)
+	api.GET("/posts/:post_id/comments/:comment_id/replies", middlewares.RequireScope(middlewares.ScopeCommentsRead), ctrl.GetReplies)
 
 	// Comment actions
-	api.PUT("/comments/:comment_id", ctrl.UpdateComment)
-	api.DELETE("/comments/:comment_id", ctrl.DeleteComment)
+	api.PUT("/comments/:comment_id", middlewares.RequireScope(middlewares.ScopeCommentsWrite), middlewares.RequireVerifiedEmail(), ctrl.UpdateComment)
+	api.DELETE("/comments/:comment_id", middlewares.RequireScope(middlewares.ScopeCommentsWrite), ctrl.DeleteComment)
 }
@@ -4,6 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"go-sosmed/internal/post"
+	"go-sosmed/internal/setting"
+	"go-sosmed/pkg/pagination"
+	"go-sosmed/pkg/rbac"
+)
+
+var (
+	ErrBlocked            = errors.New("you cannot interact with this user")
+	ErrCommentsRestricted = errors.New("the author has limited who can comment on this post")
 )
 
 type Service interface {
@@ -11,15 +19,16 @@ type Service interface {
 	CreateComment(comment *Comment) (*Comment, error)
 	ReplyToComment(userID uint, parentID uint, postID uint, content string) (*Comment, error)
 	UpdateComment(userID uint, commentID uint, req UpdateCommentRequest) (*Comment, error)
-	DeleteComment(userID uint, commentID uint) error
-	GetCommentTree(postID uint) ([]Comment, error)
-	GetReplies(commentID uint) ([]Comment, error)
+	DeleteComment(userID uint, userRole string, commentID uint) error
+	GetCommentTree(postID, viewerID uint, page pagination.Page) ([]Comment, string, error)
+	GetReplies(commentID, viewerID uint, page pagination.Page) ([]Comment, string, error)
 	GetByID(commentID uint) (*Comment, error)
 }
 
 type service struct {
 	commentRepo Repository
 	postRepo    post.Repository
+	settings    setting.Reader
 }
 
 // GetByID implements Service.
@@ -27,7 +36,64 @@ func (s *service) GetByID(commentID uint) (*Comment, error) {
 	return s.commentRepo.GetByID(commentID)
 }
 
+// checkCanComment menolak komentar/reply jika post tidak terlihat oleh penulis
+// (akun private, khusus follower, saling memblokir), jika penulis dan user yang
+// dibalas saling memblokir, atau jika pengaturan who_can_comment pemilik post
+// tidak mengizinkan penulis berkomentar
+func (s *service) checkCanComment(comment *Comment) error {
+	p, err := s.postRepo.FindDetailByID(comment.PostID, comment.UserID)
+	// draft dan post terjadwal belum bisa dikomentari, termasuk oleh penulisnya
+	if err != nil || p.Status != post.StatusPublished {
+		return errors.New("post not found")
+	}
+	if err := s.checkCommentAudience(p.AuthorID, comment.UserID); err != nil {
+		return err
+	}
+	targets := []uint{p.AuthorID}
+	if comment.ReplyToUserID != nil {
+		targets = append(targets, *comment.ReplyToUserID)
+	}
+	for _, target := range targets {
+		blocked, err := s.commentRepo.IsBlocked(comment.UserID, target)
+		if err != nil {
+			return fmt.Errorf("failed to check block status: %w", err)
+		}
+		if blocked {
+			return ErrBlocked
+		}
+	}
+	return nil
+}
+
+// checkCommentAudience menerapkan pengaturan who_can_comment milik pemilik post.
+// Pemilik post selalu boleh berkomentar di post-nya sendiri.
+func (s *service) checkCommentAudience(authorID, userID uint) error {
+	if authorID == userID {
+		return nil
+	}
+	settings, err := s.settings.Get(authorID)
+	if err != nil {
+		return err
+	}
+	switch settings.WhoCanComment {
+	case setting.AudienceNobody:
+		return ErrCommentsRestricted
+	case setting.AudienceFollowers:
+		following, err := s.commentRepo.IsFollowing(userID, authorID)
+		if err != nil {
+			return fmt.Errorf("failed to check follow status: %w", err)
+		}
+		if !following {
+			return ErrCommentsRestricted
+		}
+	}
+	return nil
+}
+
 func (s *service) CreateComment(comment *Comment) (*Comment, error) {
+	if err := s.checkCanComment(comment); err != nil {
+		return nil, err
+	}
 	if err := s.commentRepo.Create(comment); err != nil {
 		return nil, err
 	}
@@ -35,13 +101,16 @@ func (s *service) CreateComment(comment *Comment) (*Comment, error) {
 	return s.commentRepo.GetByID(comment.ID)
 }
 
-func (s *service) DeleteComment(userID uint, commentID uint) error {
-	isOwner, err := s.commentRepo.IsOwner(commentID, userID)
-	if err != nil {
-		return fmt.Errorf("failed to verify ownership: %w", err)
-	}
-	if !isOwner {
-		return fmt.Errorf("unauthorized")
+func (s *service) DeleteComment(userID uint, userRole string, commentID uint) error {
+	// moderator dan admin boleh menghapus komentar siapa pun
+	if !rbac.HasPermission(userRole, rbac.PermCommentDeleteAny) {
+		isOwner, err := s.commentRepo.IsOwner(commentID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to verify ownership: %w", err)
+		}
+		if !isOwner {
+			return fmt.Errorf("unauthorized")
+		}
 	}
 
 	comment, err := s.commentRepo.GetByID(commentID)
@@ -52,32 +121,43 @@ func (s *service) DeleteComment(userID uint, commentID uint) error {
 	return s.commentRepo.Delete(comment)
 }
 
-func (s *service) GetCommentTree(postID uint) ([]Comment, error) {
-	post, err := s.postRepo.FindByID(postID)
-	if err != nil {
-		return nil, errors.New("post not found")
+func (s *service) GetCommentTree(postID, viewerID uint, page pagination.Page) ([]Comment, string, error) {
+	// komentar hanya bisa dibaca oleh viewer yang boleh melihat post-nya
+	if _, err := s.postRepo.FindDetailByID(postID, viewerID); err != nil {
+		return nil, "", errors.New("post not found")
 	}
 
-	if post.DeletedAt.Valid {
-		return nil, errors.New("post not found")
+	comments, err := s.commentRepo.GetCommentTree(postID, viewerID, page)
+	if err != nil {
+		return nil, "", err
 	}
 
-	comments, err := s.commentRepo.GetCommentTree(postID)
+	comments, next := pagination.Trim(comments, page, commentCursor)
+	return comments, next, nil
+}
+
+func (s *service) GetReplies(commentID, viewerID uint, page pagination.Page) ([]Comment, string, error) {
+	parent, err := s.commentRepo.GetByID(commentID)
 	if err != nil {
-		return nil, err
+		return nil, "", errors.New("comment not found")
+	}
+	if _, err := s.postRepo.FindDetailByID(parent.PostID, viewerID); err != nil {
+		return nil, "", errors.New("post not found")
 	}
 
-	return comments, nil
-}
+	replies, err := s.commentRepo.GetReplies(commentID, viewerID, page)
+	if err != nil {
+		return nil, "", err
+	}
 
-func (s *service) GetReplies(commentID uint) ([]Comment, error) {
-	return s.commentRepo.GetReplies(commentID)
+	replies, next := pagination.Trim(replies, page, commentCursor)
+	return replies, next, nil
 }
 
 func (s *service) ReplyToComment(userID uint, targetID uint, postID uint, content string) (*Comment, error) {
 
 	target, err := s.commentRepo.GetByID(targetID)
-	if err != nil {
+	if err != nil || target.PostID != postID {
 		return nil, fmt.Errorf("target comment not found")
 	}
 
@@ -100,6 +180,9 @@ func (s *service) ReplyToComment(userID uint, targetID uint, postID uint, conten
 		ReplyToUserID: &replyToUserID,
 	}
 
+	if err := s.checkCanComment(reply); err != nil {
+		return nil, err
+	}
 	if err := s.commentRepo.Create(reply); err != nil {
 		return nil, err
 	}
@@ -137,8 +220,9 @@ func (s *service) UpdateComment(userID uint, commentID uint, req UpdateCommentRe
 	return comment, nil
 }
 
-func NewService(commentRepo Repository, postRepo post.Repository) Service {
+func NewService(commentRepo Repository, postRepo post.Repository, settings setting.Reader) Service {
 	return &service{
 		commentRepo: commentRepo,
-		postRepo:    postRepo}
+		postRepo:    postRepo,
+		settings:    settings}
 }
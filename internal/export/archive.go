@@ -0,0 +1,258 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"go-sosmed/internal/follow"
+	"go-sosmed/internal/post"
+	"go-sosmed/internal/report"
+	"go-sosmed/internal/token"
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
+	"go-sosmed/pkg/utils"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// buildArchive mengumpulkan data user dari repository masing-masing domain dan
+// menuliskannya ke file ZIP. File ditulis ke nama sementara lalu di-rename agar
+// arsip yang setengah jadi tidak pernah tercatat sebagai siap.
+func (s *service) buildArchive(export *DataExport, u *user.User) (string, int64, error) {
+	if err := os.MkdirAll(s.cfg.DataExportDir, 0o700); err != nil {
+		return "", 0, fmt.Errorf("failed to create export dir: %w", err)
+	}
+
+	suffix, err := token.GenerateRandomString(8)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to generate file name: %w", err)
+	}
+	filePath := filepath.Join(s.cfg.DataExportDir, fmt.Sprintf("export-%d-%s.zip", export.ID, suffix))
+	tmpPath := filePath + ".tmp"
+
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	zw := zip.NewWriter(f)
+	writeErr := s.writeArchive(zw, u)
+	if err := zw.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if err := f.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if writeErr != nil {
+		os.Remove(tmpPath)
+		return "", 0, fmt.Errorf("failed to write archive: %w", writeErr)
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return "", 0, fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to stat archive: %w", err)
+	}
+	return filePath, info.Size(), nil
+}
+
+func (s *service) writeArchive(zw *zip.Writer, u *user.User) error {
+	histories, err := s.userRepo.FindUsernameHistoryByUser(u.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load username history: %w", err)
+	}
+	previousNames := make([]string, 0, len(histories))
+	for _, h := range histories {
+		previousNames = append(previousNames, h.Username)
+	}
+
+	if err := writeJSON(zw, "profile.json", profileFile{
+		ID:              u.ID,
+		Username:        u.Username,
+		PreviousNames:   previousNames,
+		Email:           u.Email,
+		DisplayName:     u.DisplayName,
+		Bio:             u.Bio,
+		Location:        u.Location,
+		Links:           u.Links,
+		Birthday:        u.Birthday,
+		Avatar:          u.Avatar,
+		Banner:          u.Banner,
+		Role:            u.Role,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		TwoFactor:       u.TwoFactorEnabled,
+		FollowersCount:  u.FollowersCount,
+		FollowingCount:  u.FollowingCount,
+		ExportedAt:      time.Now(),
+	}); err != nil {
+		return err
+	}
+	if u.Avatar != "" {
+		if _, err := copyUpload(zw, "avatar", u.Avatar); err != nil {
+			return err
+		}
+	}
+	if u.Banner != "" {
+		if _, err := copyUpload(zw, "banner", u.Banner); err != nil {
+			return err
+		}
+	}
+
+	// posts, termasuk yang diarsipkan dan draft, beserta gambarnya
+	posts, err := pagination.Collect(pagination.MaxLimit, func(page pagination.Page) ([]*post.Post, error) {
+		return s.postRepo.FindByCurrentUser(u.ID, u.ID, page)
+	}, post.PostCursor)
+	if err != nil {
+		return fmt.Errorf("failed to load posts: %w", err)
+	}
+	drafts, err := pagination.Collect(pagination.MaxLimit, func(page pagination.Page) ([]*post.Post, error) {
+		return s.postRepo.FindDrafts(u.ID, page)
+	}, post.PostCursor)
+	if err != nil {
+		return fmt.Errorf("failed to load drafts: %w", err)
+	}
+	posts = append(posts, drafts...)
+	postFiles := make([]postFile, 0, len(posts))
+	for _, p := range posts {
+		entry := postFile{PostResponse: post.ToPostResponse(p)}
+		if p.Image != "" {
+			name, err := copyUpload(zw, fmt.Sprintf("images/post-%d", p.ID), p.Image)
+			if err != nil {
+				return err
+			}
+			entry.ImageFile = name
+		}
+		postFiles = append(postFiles, entry)
+	}
+	if err := writeJSON(zw, "posts.json", postFiles); err != nil {
+		return err
+	}
+
+	comments, err := s.commentRepo.FindByUserID(u.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load comments: %w", err)
+	}
+	commentFiles := make([]commentFile, 0, len(comments))
+	for _, c := range comments {
+		commentFiles = append(commentFiles, commentFile{
+			ID:            c.ID,
+			PostID:        c.PostID,
+			ParentID:      c.ParentID,
+			ReplyToUserID: c.ReplyToUserID,
+			Content:       c.Content,
+			Edited:        c.Edited,
+			CreatedAt:     c.CreatedAt,
+		})
+	}
+	if err := writeJSON(zw, "comments.json", commentFiles); err != nil {
+		return err
+	}
+
+	liked, err := pagination.Collect(pagination.MaxLimit, func(page pagination.Page) ([]post.Post, error) {
+		return s.likeRepo.GetPostsLikedByUser(u.ID, u.ID, page)
+	}, post.LikedPostCursor)
+	if err != nil {
+		return fmt.Errorf("failed to load likes: %w", err)
+	}
+	likeFiles := make([]likeFile, 0, len(liked))
+	for _, p := range liked {
+		likeFiles = append(likeFiles, likeFile{
+			PostID:   p.ID,
+			Title:    p.Title,
+			AuthorID: p.AuthorID,
+			Author:   p.Author.Username,
+		})
+	}
+	if err := writeJSON(zw, "likes.json", likeFiles); err != nil {
+		return err
+	}
+
+	followers, err := pagination.Collect(pagination.MaxLimit, func(page pagination.Page) ([]*follow.Follow, error) {
+		return s.followRepo.FindFollowersByUserID(u.ID, page)
+	}, follow.FollowCursor)
+	if err != nil {
+		return fmt.Errorf("failed to load followers: %w", err)
+	}
+	following, err := pagination.Collect(pagination.MaxLimit, func(page pagination.Page) ([]*follow.Follow, error) {
+		return s.followRepo.FindFollowingByUserID(u.ID, page)
+	}, follow.FollowCursor)
+	if err != nil {
+		return fmt.Errorf("failed to load following: %w", err)
+	}
+	follows := followsFile{
+		Followers: make([]*follow.FollowerResponse, 0, len(followers)),
+		Following: make([]*follow.FollowingResponse, 0, len(following)),
+	}
+	for _, f := range followers {
+		follows.Followers = append(follows.Followers, follow.ToFollowerResponse(f))
+	}
+	for _, f := range following {
+		follows.Following = append(follows.Following, follow.ToFollowingResponse(f))
+	}
+	if err := writeJSON(zw, "follows.json", follows); err != nil {
+		return err
+	}
+
+	reports, err := s.reportRepo.FindByUserID(u.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load reports: %w", err)
+	}
+	reportFiles := make([]*report.ReportResponse, 0, len(reports))
+	for _, r := range reports {
+		reportFiles = append(reportFiles, report.ToReportResponse(r))
+	}
+	return writeJSON(zw, "reports.json", reportFiles)
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// copyUpload menyalin file upload ke dalam arsip dan mengembalikan namanya di arsip.
+// File yang sudah tidak ada di server dilewati.
+func copyUpload(zw *zip.Writer, name, uploadPath string) (string, error) {
+	src, err := os.Open(utils.LocalPath(uploadPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to open %s: %w", uploadPath, err)
+	}
+	defer src.Close()
+
+	name += path.Ext(uploadPath)
+	w, err := zw.Create(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		return "", fmt.Errorf("failed to copy %s: %w", uploadPath, err)
+	}
+	return name, nil
+}
+
+// removeArchive menghapus file arsip, file yang sudah tidak ada dianggap sukses
+func removeArchive(filePath string) error {
+	if filePath == "" {
+		return nil
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
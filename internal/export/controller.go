@@ -0,0 +1,125 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"go-sosmed/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service Service
+}
+
+// helper function to get userID from context
+func GetUserIDFromContext(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	uid, ok := userID.(uint)
+	return uid, ok
+}
+
+// RequestExport godoc
+// @Summary Request data export
+// @Description Start building a ZIP archive with the current user's profile, posts, comments, likes, follows and reports. The download link is sent by email and shown by GET /api/users/me/export once ready.
+// @Tags Data Export
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/export [post]
+func (ctrl *Controller) RequestExport(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	export, err := ctrl.service.RequestExport(userID)
+	if err != nil {
+		var tooSoonErr *ExportTooSoonError
+		switch {
+		case errors.As(err, &tooSoonErr):
+			c.Header("Retry-After", strconv.Itoa(int(tooSoonErr.RetryAfter.Seconds())+1))
+			response.Error(c, http.StatusTooManyRequests, err.Error())
+		case errors.Is(err, ErrExportInProgress):
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "data export requested, you will receive an email when it is ready", export)
+}
+
+// GetExport godoc
+// @Summary Get data export status
+// @Description Get the status of the latest data export. A time-limited download_url is included once the archive is ready.
+// @Tags Data Export
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/export [get]
+func (ctrl *Controller) GetExport(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	export, err := ctrl.service.GetLatestExport(userID)
+	if err != nil {
+		if errors.Is(err, ErrExportNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "data export retrieved successfully", export)
+}
+
+// Download godoc
+// @Summary Download data export
+// @Description Download a data export archive using the signed link from the email or the export status endpoint
+// @Tags Data Export
+// @Produce application/zip
+// @Param token query string true "Signed download token"
+// @Success 200 {file} file
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 410 {object} response.ErrorResponse
+// @Router /api/exports/download [get]
+func (ctrl *Controller) Download(c *gin.Context) {
+	export, err := ctrl.service.ResolveDownload(c.Query("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidDownload):
+			response.Error(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrExportUnavailable):
+			response.Error(c, http.StatusGone, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(export.FilePath, fmt.Sprintf("go-sosmed-data-export-%d.zip", export.ID))
+}
+
+func NewController(service Service) *Controller {
+	return &Controller{service: service}
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"fmt"
+	"go-sosmed/internal/follow"
+	"go-sosmed/internal/post"
+	"go-sosmed/internal/user"
+	"time"
+)
+
+type StatusType = string
+
+const (
+	StatusPending    StatusType = "pending"
+	StatusProcessing StatusType = "processing"
+	StatusReady      StatusType = "ready"
+	StatusFailed     StatusType = "failed"
+	StatusExpired    StatusType = "expired"
+)
+
+// DataExport adalah satu permintaan export data pribadi user.
+// Arsip ZIP dibuat di background oleh worker, lalu bisa di-download sampai ExpiresAt.
+type DataExport struct {
+	ID          uint       `gorm:"primaryKey"`
+	UserID      uint       `gorm:"not null;index"`
+	Status      StatusType `gorm:"size:20;default:'pending';index"`
+	FilePath    string     `gorm:"type:text"`
+	FileSize    int64
+	Error       string `gorm:"type:text"`
+	ExpiresAt   *time.Time
+	ClaimedAt   *time.Time // waktu worker mulai memproses, dipakai untuk mendeteksi worker yang mati
+	CompletedAt *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+type DataExportResponse struct {
+	ID          uint       `json:"id"`
+	Status      string     `json:"status"`
+	FileSize    int64      `json:"file_size,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ExportTooSoonError dikembalikan jika user meminta export lagi sebelum cooldown habis
+type ExportTooSoonError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ExportTooSoonError) Error() string {
+	return fmt.Sprintf("a data export was requested recently, try again in %s", e.RetryAfter.Round(time.Minute))
+}
+
+// isi arsip, sengaja dipisah dari response API agar format file stabil
+
+type profileFile struct {
+	ID              uint               `json:"id"`
+	Username        string             `json:"username"`
+	PreviousNames   []string           `json:"previous_usernames"`
+	Email           string             `json:"email"`
+	DisplayName     string             `json:"display_name"`
+	Bio             string             `json:"bio"`
+	Location        string             `json:"location"`
+	Links           []user.ProfileLink `json:"links"`
+	Birthday        *time.Time         `json:"birthday"`
+	Avatar          string             `json:"avatar"`
+	Banner          string             `json:"banner"`
+	Role            string             `json:"role"`
+	EmailVerifiedAt *time.Time         `json:"email_verified_at"`
+	TwoFactor       bool               `json:"two_factor_enabled"`
+	FollowersCount  int64              `json:"followers_count"`
+	FollowingCount  int64              `json:"following_count"`
+	ExportedAt      time.Time          `json:"exported_at"`
+}
+
+type postFile struct {
+	*post.PostResponse
+	ImageFile string `json:"image_file,omitempty"` // path gambar di dalam arsip
+}
+
+type commentFile struct {
+	ID            uint      `json:"id"`
+	PostID        uint      `json:"post_id"`
+	ParentID      *uint     `json:"parent_id,omitempty"`
+	ReplyToUserID *uint     `json:"reply_to_user_id,omitempty"`
+	Content       string    `json:"content"`
+	Edited        bool      `json:"edited"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type likeFile struct {
+	PostID   uint   `json:"post_id"`
+	Title    string `json:"title"`
+	AuthorID uint   `json:"author_id"`
+	Author   string `json:"author"`
+}
+
+type followsFile struct {
+	Followers []*follow.FollowerResponse  `json:"followers"`
+	Following []*follow.FollowingResponse `json:"following"`
+}
@@ -0,0 +1,164 @@
+package export
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(export *DataExport) error
+	FindByID(id uint) (*DataExport, error)
+	FindLatestByUserID(userID uint) (*DataExport, error)
+	FindPending(limit int) ([]*DataExport, error)
+	Claim(id uint, claimedAt time.Time) (bool, error)
+	RefreshClaim(id uint, claimedAt, refreshedAt time.Time) (bool, error)
+	MarkReady(id uint, claimedAt time.Time, filePath string, fileSize int64, expiresAt time.Time) (bool, error)
+	MarkFailed(id uint, claimedAt time.Time, reason string) (bool, error)
+	RequeueStale(claimedBefore time.Time) (int64, error)
+	FindExpired(now time.Time, limit int) ([]*DataExport, error)
+	MarkExpired(id uint) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// Create implements Repository.
+func (r *repository) Create(export *DataExport) error {
+	return r.db.Create(export).Error
+}
+
+// FindByID implements Repository.
+func (r *repository) FindByID(id uint) (*DataExport, error) {
+	var export DataExport
+	if err := r.db.First(&export, id).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FindLatestByUserID implements Repository.
+func (r *repository) FindLatestByUserID(userID uint) (*DataExport, error) {
+	var export DataExport
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FindPending implements Repository.
+func (r *repository) FindPending(limit int) ([]*DataExport, error) {
+	var exports []*DataExport
+	err := r.db.
+		Where("status = ?", StatusPending).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+// Claim implements Repository.
+// Update bersyarat agar satu export hanya diproses oleh satu worker.
+func (r *repository) Claim(id uint, claimedAt time.Time) (bool, error) {
+	result := r.db.Model(&DataExport{}).
+		Where("id = ? AND status = ?", id, StatusPending).
+		Updates(map[string]interface{}{"status": StatusProcessing, "claimed_at": claimedAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// heldClaim membatasi update ke export yang masih dipegang worker dengan claim
+// claimedAt, bukan claim baru setelah export di-requeue
+func heldClaim(id uint, claimedAt time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ? AND status = ? AND claimed_at = ?", id, StatusProcessing, claimedAt)
+	}
+}
+
+// RefreshClaim implements Repository.
+// Dipanggil berkala selama arsip dibangun agar export tidak dianggap terhenti.
+func (r *repository) RefreshClaim(id uint, claimedAt, refreshedAt time.Time) (bool, error) {
+	result := r.db.Model(&DataExport{}).
+		Scopes(heldClaim(id, claimedAt)).
+		Update("claimed_at", refreshedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MarkReady implements Repository.
+func (r *repository) MarkReady(id uint, claimedAt time.Time, filePath string, fileSize int64, expiresAt time.Time) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&DataExport{}).
+		Scopes(heldClaim(id, claimedAt)).
+		Updates(map[string]interface{}{
+			"status":       StatusReady,
+			"file_path":    filePath,
+			"file_size":    fileSize,
+			"expires_at":   expiresAt,
+			"completed_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MarkFailed implements Repository.
+func (r *repository) MarkFailed(id uint, claimedAt time.Time, reason string) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&DataExport{}).
+		Scopes(heldClaim(id, claimedAt)).
+		Updates(map[string]interface{}{
+			"status":       StatusFailed,
+			"error":        reason,
+			"completed_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RequeueStale implements Repository.
+// Export yang di-claim sebelum claimedBefore dianggap terhenti di tengah jalan
+// (misal instance worker mati) dan dikembalikan ke antrian. Export yang sedang
+// diproses instance lain tidak tersentuh selama claim-nya belum melewati batas.
+func (r *repository) RequeueStale(claimedBefore time.Time) (int64, error) {
+	result := r.db.Model(&DataExport{}).
+		Where("status = ? AND (claimed_at IS NULL OR claimed_at < ?)", StatusProcessing, claimedBefore).
+		Updates(map[string]interface{}{"status": StatusPending, "claimed_at": nil})
+	return result.RowsAffected, result.Error
+}
+
+// FindExpired implements Repository.
+func (r *repository) FindExpired(now time.Time, limit int) ([]*DataExport, error) {
+	var exports []*DataExport
+	err := r.db.
+		Where("status = ? AND expires_at <= ?", StatusReady, now).
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+// MarkExpired implements Repository.
+func (r *repository) MarkExpired(id uint) error {
+	return r.db.Model(&DataExport{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":    StatusExpired,
+			"file_path": "",
+		}).Error
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db}
+}
@@ -0,0 +1,19 @@
+package export
+
+import (
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
+	// link download sudah ditandatangani, tidak perlu header Authorization
+	r.GET("/api/exports/download", ctrl.Download)
+
+	// export berisi seluruh data pribadi, hanya dari login biasa
+	api := r.Group("/api/users/me")
+	api.Use(middlewares.Authenticate(cfg), middlewares.RequireSessionAuth())
+	api.POST("/export", ctrl.RequestExport)
+	api.GET("/export", ctrl.GetExport)
+}
@@ -0,0 +1,397 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"go-sosmed/internal/comment"
+	"go-sosmed/internal/follow"
+	"go-sosmed/internal/like"
+	"go-sosmed/internal/post"
+	"go-sosmed/internal/report"
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/jwtkeys"
+	"go-sosmed/pkg/mailer"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExportInProgress  = errors.New("a data export is already being prepared")
+	ErrExportNotFound    = errors.New("data export not found")
+	ErrInvalidDownload   = errors.New("invalid or expired download link")
+	ErrExportUnavailable = errors.New("data export is no longer available")
+)
+
+const (
+	purposeDataExport = "data_export"
+
+	// jumlah export maksimal yang diproses / dibersihkan per putaran worker
+	workerBatchSize = 5
+	workerInterval  = time.Minute
+	// export yang masih processing lebih lama dari ini dikembalikan ke antrian
+	claimTimeout = 30 * time.Minute
+	// claim diperbarui jauh sebelum claimTimeout selama arsip masih dibangun
+	claimRefreshInterval = claimTimeout / 3
+)
+
+type Service interface {
+	RequestExport(userID uint) (*DataExportResponse, error)
+	GetLatestExport(userID uint) (*DataExportResponse, error)
+	ResolveDownload(downloadToken string) (*DataExport, error)
+	ProcessPending() (int, error)
+	CleanupExpired() (int, error)
+	StartWorker()
+}
+
+type service struct {
+	repo        Repository
+	userRepo    user.Repository
+	postRepo    post.Repository
+	commentRepo comment.Repository
+	likeRepo    like.Repository
+	followRepo  follow.Repository
+	reportRepo  report.Repository
+	mailer      mailer.Mailer
+	cfg         *config.Config
+	// membangunkan worker segera setelah ada permintaan baru
+	wake chan struct{}
+}
+
+// downloadClaims adalah token link download, berlaku sampai arsip kedaluwarsa
+type downloadClaims struct {
+	Purpose  string `json:"purpose"`
+	ExportID uint   `json:"export_id"`
+	jwt.RegisteredClaims
+}
+
+func (s *service) expiresIn() time.Duration {
+	ttl, err := time.ParseDuration(s.cfg.DataExportExpires)
+	if err != nil || ttl <= 0 {
+		ttl = 48 * time.Hour
+	}
+	return ttl
+}
+
+func (s *service) cooldown() time.Duration {
+	cooldown, err := time.ParseDuration(s.cfg.DataExportCooldown)
+	if err != nil || cooldown < 0 {
+		cooldown = 24 * time.Hour
+	}
+	return cooldown
+}
+
+func (s *service) downloadURL(export *DataExport) (string, error) {
+	claims := downloadClaims{
+		Purpose:  purposeDataExport,
+		ExportID: export.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(export.UserID), 10),
+			ExpiresAt: jwt.NewNumericDate(*export.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	signed, err := jwtkeys.Get().Sign(claims, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to sign download link: %w", err)
+	}
+	return strings.TrimRight(s.cfg.AppURL, "/") + "/api/exports/download?token=" + url.QueryEscape(signed), nil
+}
+
+func (s *service) toResponse(export *DataExport) (*DataExportResponse, error) {
+	resp := &DataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+	}
+	if export.Status == StatusReady && export.ExpiresAt != nil && export.ExpiresAt.After(time.Now()) {
+		link, err := s.downloadURL(export)
+		if err != nil {
+			return nil, err
+		}
+		resp.DownloadURL = link
+		resp.FileSize = export.FileSize
+		resp.ExpiresAt = export.ExpiresAt
+	}
+	return resp, nil
+}
+
+// RequestExport implements Service.
+// Export dibuat di background; user hanya bisa punya satu export yang sedang diproses.
+func (s *service) RequestExport(userID uint) (*DataExportResponse, error) {
+	latest, err := s.repo.FindLatestByUserID(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check previous export: %w", err)
+	}
+	if latest != nil {
+		switch latest.Status {
+		case StatusPending, StatusProcessing:
+			return nil, ErrExportInProgress
+		case StatusReady, StatusExpired:
+			if retry := time.Until(latest.CreatedAt.Add(s.cooldown())); retry > 0 {
+				return nil, &ExportTooSoonError{RetryAfter: retry}
+			}
+		}
+	}
+
+	export := &DataExport{UserID: userID, Status: StatusPending}
+	if err := s.repo.Create(export); err != nil {
+		return nil, fmt.Errorf("failed to create data export: %w", err)
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return s.toResponse(export)
+}
+
+// GetLatestExport implements Service.
+func (s *service) GetLatestExport(userID uint) (*DataExportResponse, error) {
+	export, err := s.repo.FindLatestByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExportNotFound
+		}
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	return s.toResponse(export)
+}
+
+// ResolveDownload implements Service.
+// Memvalidasi token link download dan mengembalikan export yang siap diunduh.
+func (s *service) ResolveDownload(downloadToken string) (*DataExport, error) {
+	claims := &downloadClaims{}
+	keyring := jwtkeys.Get()
+	parsed, err := jwt.ParseWithClaims(downloadToken, claims, keyring.Keyfunc, jwt.WithValidMethods(keyring.ValidMethods()))
+	if err != nil || !parsed.Valid || claims.Purpose != purposeDataExport {
+		return nil, ErrInvalidDownload
+	}
+
+	export, err := s.repo.FindByID(claims.ExportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidDownload
+		}
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	if strconv.FormatUint(uint64(export.UserID), 10) != claims.Subject {
+		return nil, ErrInvalidDownload
+	}
+	if export.Status != StatusReady || export.ExpiresAt == nil || !export.ExpiresAt.After(time.Now()) {
+		return nil, ErrExportUnavailable
+	}
+	return export, nil
+}
+
+// ProcessPending implements Service.
+func (s *service) ProcessPending() (int, error) {
+	exports, err := s.repo.FindPending(workerBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find pending exports: %w", err)
+	}
+
+	processed := 0
+	for _, export := range exports {
+		claimedAt := claimTime()
+		claimed, err := s.repo.Claim(export.ID, claimedAt)
+		if err != nil {
+			log.Printf("Warning: failed to claim data export %d: %v", export.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		s.process(export, claimedAt)
+		processed++
+	}
+	return processed, nil
+}
+
+// claimTime dibulatkan ke detik agar sama persis dengan nilai yang tersimpan
+// di kolom DATETIME, karena dipakai sebagai syarat update berikutnya
+func claimTime() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+// keepClaim memperbarui claimed_at secara berkala sampai fungsi yang
+// dikembalikan dipanggil. Fungsi itu mengembalikan claimed_at terakhir.
+func (s *service) keepClaim(exportID uint, claimedAt time.Time) func() time.Time {
+	done := make(chan struct{})
+	stopped := make(chan time.Time)
+	go func() {
+		ticker := time.NewTicker(claimRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				stopped <- claimedAt
+				return
+			case <-ticker.C:
+				refreshedAt := claimTime()
+				refreshed, err := s.repo.RefreshClaim(exportID, claimedAt, refreshedAt)
+				if err != nil {
+					log.Printf("Warning: failed to refresh claim of data export %d: %v", exportID, err)
+					continue
+				}
+				if refreshed {
+					claimedAt = refreshedAt
+				}
+			}
+		}
+	}()
+	return func() time.Time {
+		close(done)
+		return <-stopped
+	}
+}
+
+// process membangun arsip satu export lalu memberi tahu user lewat email.
+// Hasilnya hanya disimpan jika claim masih dipegang; jika export sudah
+// di-requeue dan diambil worker lain, arsip ini dibuang.
+func (s *service) process(export *DataExport, claimedAt time.Time) {
+	u, err := s.userRepo.FindCurrentUserDetail(export.UserID)
+	if err != nil {
+		s.fail(export, claimedAt, fmt.Errorf("failed to load user: %w", err))
+		return
+	}
+
+	release := s.keepClaim(export.ID, claimedAt)
+	filePath, size, err := s.buildArchive(export, u)
+	claimedAt = release()
+	if err != nil {
+		s.fail(export, claimedAt, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(s.expiresIn())
+	marked, err := s.repo.MarkReady(export.ID, claimedAt, filePath, size, expiresAt)
+	if err != nil || !marked {
+		if err != nil {
+			log.Printf("Warning: failed to mark data export %d as ready: %v", export.ID, err)
+		} else {
+			log.Printf("Warning: data export %d was claimed by another worker, discarding archive", export.ID)
+		}
+		if err := removeArchive(filePath); err != nil {
+			log.Printf("Warning: failed to delete data export %d: %v", export.ID, err)
+		}
+		return
+	}
+	export.Status = StatusReady
+	export.ExpiresAt = &expiresAt
+
+	link, err := s.downloadURL(export)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	if err := s.mailer.Send(mailer.Message{
+		To:      u.Email,
+		Subject: "Your data export is ready",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe copy of your data you requested is ready. Download it from the link below:\n\n%s\n\nThis link expires at %s. If you did not request this export, please change your password.\n",
+			u.Username, link, expiresAt.Format(time.RFC1123),
+		),
+	}); err != nil {
+		log.Printf("Warning: failed to send data export email: %v", err)
+	}
+}
+
+func (s *service) fail(export *DataExport, claimedAt time.Time, err error) {
+	log.Printf("Warning: data export %d failed: %v", export.ID, err)
+	marked, err := s.repo.MarkFailed(export.ID, claimedAt, err.Error())
+	if err != nil {
+		log.Printf("Warning: failed to mark data export %d as failed: %v", export.ID, err)
+	} else if !marked {
+		log.Printf("Warning: data export %d was claimed by another worker, not marking as failed", export.ID)
+	}
+}
+
+// CleanupExpired implements Service.
+// Menghapus arsip yang masa berlakunya sudah habis.
+func (s *service) CleanupExpired() (int, error) {
+	exports, err := s.repo.FindExpired(time.Now(), workerBatchSize*10)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired exports: %w", err)
+	}
+
+	cleaned := 0
+	for _, export := range exports {
+		if err := removeArchive(export.FilePath); err != nil {
+			log.Printf("Warning: failed to delete data export %d: %v", export.ID, err)
+			continue
+		}
+		if err := s.repo.MarkExpired(export.ID); err != nil {
+			log.Printf("Warning: failed to mark data export %d as expired: %v", export.ID, err)
+			continue
+		}
+		cleaned++
+	}
+	return cleaned, nil
+}
+
+// StartWorker implements Service.
+// Memproses antrian export dan membersihkan arsip kedaluwarsa di background.
+func (s *service) StartWorker() {
+	go func() {
+		ticker := time.NewTicker(workerInterval)
+		defer ticker.Stop()
+		for {
+			if requeued, err := s.repo.RequeueStale(time.Now().Add(-claimTimeout)); err != nil {
+				log.Printf("Warning: failed to requeue interrupted data exports: %v", err)
+			} else if requeued > 0 {
+				log.Printf("Requeued %d interrupted data exports", requeued)
+			}
+			for {
+				processed, err := s.ProcessPending()
+				if err != nil {
+					log.Printf("Warning: %v", err)
+				}
+				if processed < workerBatchSize {
+					break
+				}
+			}
+			if _, err := s.CleanupExpired(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.wake:
+			}
+		}
+	}()
+}
+
+func NewService(
+	repo Repository,
+	userRepo user.Repository,
+	postRepo post.Repository,
+	commentRepo comment.Repository,
+	likeRepo like.Repository,
+	followRepo follow.Repository,
+	reportRepo report.Repository,
+	mailer mailer.Mailer,
+	cfg *config.Config,
+) Service {
+	return &service{
+		repo:        repo,
+		userRepo:    userRepo,
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+		likeRepo:    likeRepo,
+		followRepo:  followRepo,
+		reportRepo:  reportRepo,
+		mailer:      mailer,
+		cfg:         cfg,
+		wake:        make(chan struct{}, 1),
+	}
+}
@@ -0,0 +1,62 @@
+package feed
+
+import (
+	"go-sosmed/pkg/pagination"
+	"go-sosmed/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service Service
+}
+
+// helper function to get userID from context
+func GetUserIDFromContext(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	uid, ok := userID.(uint)
+	return uid, ok
+}
+
+// GetForYou godoc
+// @Summary Get the "For You" feed
+// @Description Ranked feed of recent posts from followed authors, authors followed by people you follow and posts liked by people you follow. Posts are scored by affinity, engagement velocity and recency, with fewer consecutive posts from the same author. For the chronological feed use /api/posts/following.
+// @Tags Feed
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Security BearerAuth
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/feed/for-you [get]
+func (ctrl *Controller) GetForYou(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	posts, next, err := ctrl.service.GetForYou(userID, page)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Paginated(c, http.StatusOK, "feed retrieved successfully", posts, next)
+}
+
+func NewController(service Service) *Controller {
+	return &Controller{service: service}
+}
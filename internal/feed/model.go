@@ -0,0 +1,21 @@
+package feed
+
+import "time"
+
+// Candidate adalah satu post yang layak masuk feed "For You" beserta sinyal
+// yang dipakai untuk scoring. Diisi oleh repository, dinilai oleh Scorer.
+type Candidate struct {
+	PostID    uint
+	AuthorID  uint
+	CreatedAt time.Time
+	// penulis di-follow langsung oleh viewer
+	FromFollowing bool
+	// penulis di-follow oleh user yang di-follow viewer
+	FromFriendOfFriend bool
+	// jumlah like dari user yang di-follow viewer
+	LikedByFollowing int64
+	LikeCount        int64
+	CommentCount     int64
+	// skor akhir setelah penalti penulis, diisi oleh Rank
+	Score float64
+}
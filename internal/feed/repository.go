@@ -0,0 +1,129 @@
+package feed
+
+import (
+	"go-sosmed/internal/mute"
+	"go-sosmed/internal/post"
+	"go-sosmed/internal/user"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	FindCandidates(viewerID uint, since, until time.Time, limit int) ([]*Candidate, error)
+	FindPostsByIDs(ids []uint, viewerID uint) ([]*post.Post, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// FindCandidates implements Repository.
+// Kandidat adalah post dalam rentang waktu dari penulis yang di-follow viewer,
+// penulis yang di-follow oleh follow-an viewer, atau post yang di-like oleh
+// follow-an viewer. Post milik viewer sendiri tidak ikut. Himpunan post
+// dipersempit lebih dulu lewat subquery IN agar sinyal per post hanya dihitung
+// untuk kandidat, bukan untuk semua post dalam rentang waktu.
+func (r *repository) FindCandidates(viewerID uint, since, until time.Time, limit int) ([]*Candidate, error) {
+	accepted := user.FollowStatusAccepted
+
+	var candidates []*Candidate
+	err := r.db.
+		Table("posts").
+		Select(`
+			posts.id AS post_id,
+			posts.author_id,
+			posts.created_at,
+			EXISTS (
+				SELECT 1 FROM follows
+				WHERE follows.follower_id = ?
+				AND follows.following_id = posts.author_id
+				AND follows.status = ?
+			) AS from_following,
+			EXISTS (
+				SELECT 1 FROM follows AS viewer_follows
+				JOIN follows AS second_follows
+				ON second_follows.follower_id = viewer_follows.following_id
+				AND second_follows.status = ?
+				WHERE viewer_follows.follower_id = ?
+				AND viewer_follows.status = ?
+				AND second_follows.following_id = posts.author_id
+			) AS from_friend_of_friend,
+			(
+				SELECT COUNT(*) FROM likes
+				JOIN follows
+				ON follows.following_id = likes.user_id
+				AND follows.follower_id = ?
+				AND follows.status = ?
+				WHERE likes.post_id = posts.id
+			) AS liked_by_following,
+			posts.like_count,
+			posts.comment_count
+		`, viewerID, accepted, accepted, viewerID, accepted, viewerID, accepted).
+		Where("posts.deleted_at IS NULL AND posts.archived = ?", false).
+		Where("posts.author_id != ?", viewerID).
+		Where("posts.created_at > ? AND posts.created_at <= ?", since, until).
+		Where(`(
+			posts.author_id IN (
+				SELECT following_id FROM follows
+				WHERE follower_id = ? AND status = ?
+				UNION
+				SELECT second_follows.following_id FROM follows AS viewer_follows
+				JOIN follows AS second_follows
+				ON second_follows.follower_id = viewer_follows.following_id
+				AND second_follows.status = ?
+				WHERE viewer_follows.follower_id = ?
+				AND viewer_follows.status = ?
+			)
+			OR posts.id IN (
+				SELECT likes.post_id FROM likes
+				JOIN follows
+				ON follows.following_id = likes.user_id
+				AND follows.follower_id = ?
+				AND follows.status = ?
+			)
+		)`, viewerID, accepted, accepted, viewerID, accepted, viewerID, accepted).
+		Scopes(
+			post.VisibleAuthors,
+			post.VisibleTo(viewerID),
+			mute.NotMuted("posts.author_id", []string{"posts.title", "posts.content"}, viewerID),
+		).
+		Order("posts.created_at DESC").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// FindPostsByIDs implements Repository.
+// Urutan hasil tidak dijamin, service yang menyusun ulang sesuai ranking.
+func (r *repository) FindPostsByIDs(ids []uint, viewerID uint) ([]*post.Post, error) {
+	var posts []*post.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+
+	err := r.db.
+		Model(&post.Post{}).
+		Select(`
+			posts.*,
+			EXISTS (
+				SELECT 1 FROM likes
+				WHERE likes.post_id = posts.id
+				AND likes.user_id = ?
+			) AS is_liked
+		`, viewerID).
+		Where("posts.id IN ?", ids).
+		Preload("Author").
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db}
+}
@@ -0,0 +1,15 @@
+package feed
+
+import (
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
+	api := r.Group("/api/feed")
+	api.Use(middlewares.Authenticate(cfg))
+
+	api.GET("/for-you", middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.GetForYou)
+}
@@ -0,0 +1,112 @@
+package feed
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Scorer memberi skor relevansi satu kandidat untuk viewer, skor lebih tinggi
+// tampil lebih dulu. Tidak menyentuh database sehingga bisa diuji terpisah.
+type Scorer interface {
+	Score(c *Candidate, now time.Time) float64
+}
+
+// ScorerFunc memungkinkan fungsi biasa dipakai sebagai Scorer
+type ScorerFunc func(c *Candidate, now time.Time) float64
+
+// Score implements Scorer.
+func (f ScorerFunc) Score(c *Candidate, now time.Time) float64 {
+	return f(c, now)
+}
+
+// Weights mengatur bobot tiap sinyal pada WeightedScorer
+type Weights struct {
+	Following        float64
+	FriendOfFriend   float64
+	LikedByFollowing float64 // dikali log(1 + jumlah like dari yang di-follow)
+	Velocity         float64 // dikali log(1 + interaksi per jam)
+	// skor berkurang setengahnya setiap HalfLife sejak post dibuat
+	HalfLife time.Duration
+}
+
+var DefaultWeights = Weights{
+	Following:        3,
+	FriendOfFriend:   1,
+	LikedByFollowing: 1.5,
+	Velocity:         2,
+	HalfLife:         12 * time.Hour,
+}
+
+// WeightedScorer menjumlahkan afinitas viewer ke post dan kecepatan engagement,
+// lalu dikalikan peluruhan berdasarkan umur post.
+type WeightedScorer struct {
+	Weights Weights
+}
+
+func NewWeightedScorer(weights Weights) *WeightedScorer {
+	return &WeightedScorer{Weights: weights}
+}
+
+// Score implements Scorer.
+func (s *WeightedScorer) Score(c *Candidate, now time.Time) float64 {
+	w := s.Weights
+	hours := now.Sub(c.CreatedAt).Hours()
+	if hours < 0 {
+		hours = 0
+	}
+
+	affinity := w.LikedByFollowing * math.Log1p(float64(c.LikedByFollowing))
+	if c.FromFollowing {
+		affinity += w.Following
+	}
+	if c.FromFriendOfFriend {
+		affinity += w.FriendOfFriend
+	}
+
+	// +2 jam agar post yang baru beberapa menit tidak langsung mendapat velocity ekstrem
+	velocity := float64(c.LikeCount+c.CommentCount) / (hours + 2)
+	engagement := w.Velocity * math.Log1p(velocity)
+
+	decay := 1.0
+	if w.HalfLife > 0 {
+		decay = math.Pow(0.5, hours/w.HalfLife.Hours())
+	}
+	return (affinity + engagement) * decay
+}
+
+// Rank mengurutkan kandidat berdasarkan skor. Agar feed tidak didominasi satu
+// akun, post berikutnya dari penulis yang sama skornya dikali authorPenalty
+// (0 < authorPenalty <= 1, 1 berarti tanpa penalti).
+func Rank(candidates []*Candidate, scorer Scorer, now time.Time, authorPenalty float64) []*Candidate {
+	type scored struct {
+		c     *Candidate
+		score float64
+	}
+	items := make([]scored, 0, len(candidates))
+	for _, c := range candidates {
+		items = append(items, scored{c: c, score: scorer.Score(c, now)})
+	}
+	byScore := func(i, j int) bool {
+		if items[i].score != items[j].score {
+			return items[i].score > items[j].score
+		}
+		return items[i].c.PostID > items[j].c.PostID
+	}
+	sort.SliceStable(items, byScore)
+
+	seen := make(map[uint]int)
+	for i := range items {
+		n := seen[items[i].c.AuthorID]
+		items[i].score *= math.Pow(authorPenalty, float64(n))
+		seen[items[i].c.AuthorID] = n + 1
+	}
+	sort.SliceStable(items, byScore)
+
+	ranked := make([]*Candidate, 0, len(items))
+	for _, it := range items {
+		it.c.Score = it.score
+		ranked = append(ranked, it.c)
+	}
+	return ranked
+}
@@ -0,0 +1,121 @@
+package feed
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWeightedScorerScore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	noDecay := DefaultWeights
+	noDecay.HalfLife = 0
+
+	tests := []struct {
+		name      string
+		weights   Weights
+		candidate Candidate
+		age       time.Duration
+		want      float64
+	}{
+		{name: "following", weights: DefaultWeights, candidate: Candidate{FromFollowing: true}, want: 3},
+		{name: "friend of friend", weights: DefaultWeights, candidate: Candidate{FromFriendOfFriend: true}, want: 1},
+		{name: "following and friend of friend", weights: DefaultWeights, candidate: Candidate{FromFollowing: true, FromFriendOfFriend: true}, want: 4},
+		{name: "liked by following", weights: DefaultWeights, candidate: Candidate{LikedByFollowing: 3}, want: 1.5 * math.Log(4)},
+		{name: "engagement velocity", weights: DefaultWeights, candidate: Candidate{LikeCount: 8, CommentCount: 2}, want: 2 * math.Log(6)},
+		{name: "halved after one half-life", weights: DefaultWeights, candidate: Candidate{FromFollowing: true}, age: 12 * time.Hour, want: 1.5},
+		{name: "quartered after two half-lives", weights: DefaultWeights, candidate: Candidate{FromFollowing: true}, age: 24 * time.Hour, want: 0.75},
+		{name: "velocity slows down with age", weights: noDecay, candidate: Candidate{LikeCount: 10}, age: 8 * time.Hour, want: 2 * math.Log(2)},
+		{name: "no decay without half-life", weights: noDecay, candidate: Candidate{FromFollowing: true}, age: 48 * time.Hour, want: 3},
+		{name: "future post is not boosted", weights: DefaultWeights, candidate: Candidate{FromFollowing: true}, age: -time.Hour, want: 3},
+		{name: "no signal", weights: DefaultWeights, candidate: Candidate{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.candidate
+			c.CreatedAt = now.Add(-tt.age)
+			got := NewWeightedScorer(tt.weights).Score(&c, now)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		candidates    []*Candidate
+		scores        map[uint]float64
+		authorPenalty float64
+		want          []uint
+	}{
+		{
+			name:          "highest score first",
+			candidates:    []*Candidate{{PostID: 1, AuthorID: 1}, {PostID: 2, AuthorID: 2}, {PostID: 3, AuthorID: 3}},
+			scores:        map[uint]float64{1: 1, 2: 3, 3: 2},
+			authorPenalty: 1,
+			want:          []uint{2, 3, 1},
+		},
+		{
+			name:          "ties broken by newest post ID",
+			candidates:    []*Candidate{{PostID: 4, AuthorID: 1}, {PostID: 9, AuthorID: 2}, {PostID: 6, AuthorID: 3}},
+			scores:        map[uint]float64{4: 1, 9: 1, 6: 1},
+			authorPenalty: 1,
+			want:          []uint{9, 6, 4},
+		},
+		{
+			name:          "repeated author is penalized",
+			candidates:    []*Candidate{{PostID: 1, AuthorID: 1}, {PostID: 2, AuthorID: 1}, {PostID: 3, AuthorID: 2}},
+			scores:        map[uint]float64{1: 10, 2: 9, 3: 6},
+			authorPenalty: 0.5,
+			want:          []uint{1, 3, 2},
+		},
+		{
+			name:          "penalty compounds per extra post",
+			candidates:    []*Candidate{{PostID: 1, AuthorID: 1}, {PostID: 2, AuthorID: 1}, {PostID: 3, AuthorID: 1}, {PostID: 4, AuthorID: 2}},
+			scores:        map[uint]float64{1: 10, 2: 10, 3: 10, 4: 3},
+			authorPenalty: 0.5,
+			want:          []uint{3, 2, 4, 1},
+		},
+		{
+			name:          "penalty of one keeps score order",
+			candidates:    []*Candidate{{PostID: 1, AuthorID: 1}, {PostID: 2, AuthorID: 1}, {PostID: 3, AuthorID: 2}},
+			scores:        map[uint]float64{1: 10, 2: 9, 3: 6},
+			authorPenalty: 1,
+			want:          []uint{1, 2, 3},
+		},
+		{
+			name:          "tie after penalty broken by post ID",
+			candidates:    []*Candidate{{PostID: 1, AuthorID: 1}, {PostID: 2, AuthorID: 1}, {PostID: 5, AuthorID: 2}},
+			scores:        map[uint]float64{1: 10, 2: 8, 5: 4},
+			authorPenalty: 0.5,
+			want:          []uint{1, 5, 2},
+		},
+		{
+			name:          "empty",
+			candidates:    nil,
+			authorPenalty: 0.5,
+			want:          []uint{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer := ScorerFunc(func(c *Candidate, _ time.Time) float64 {
+				return tt.scores[c.PostID]
+			})
+			got := []uint{}
+			for _, c := range Rank(tt.candidates, scorer, now, tt.authorPenalty) {
+				got = append(got, c.PostID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rank = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package feed
+
+import (
+	"fmt"
+	"go-sosmed/internal/post"
+	"go-sosmed/pkg/pagination"
+	"sort"
+	"time"
+)
+
+const (
+	// hanya post dalam rentang ini yang dinilai
+	candidateWindow = 7 * 24 * time.Hour
+	// jumlah kandidat terbaru yang dinilai per request
+	candidatePoolSize = 500
+	// post kedua dari penulis yang sama dikali 0.5, ketiga 0.25, dst.
+	authorPenalty = 0.5
+)
+
+type Service interface {
+	GetForYou(viewerID uint, page pagination.Page) ([]*post.PostResponse, string, error)
+}
+
+type service struct {
+	repo   Repository
+	scorer Scorer
+}
+
+// GetForYou implements Service.
+// Ranking dihitung ulang setiap request terhadap waktu snapshot yang sama.
+// Cursor feed ini menyimpan waktu snapshot di CreatedAt dan (skor, post ID)
+// post terakhir yang dikirim. Halaman berikutnya dimulai tepat setelah posisi
+// itu, bukan dari offset, sehingga skor yang berubah di antara dua request
+// tidak menggeser seluruh urutan dan mengulang atau melompati post lain.
+func (s *service) GetForYou(viewerID uint, page pagination.Page) ([]*post.PostResponse, string, error) {
+	now := time.Now()
+	if page.After != nil {
+		now = page.After.CreatedAt
+	}
+
+	candidates, err := s.repo.FindCandidates(viewerID, now.Add(-candidateWindow), now, candidatePoolSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to retrieve feed: %w", err)
+	}
+	ranked := Rank(candidates, s.scorer, now, authorPenalty)
+	if page.After != nil {
+		ranked = rankedAfter(ranked, page.After.Score, page.After.ID)
+	}
+
+	next := ""
+	if len(ranked) > page.Limit {
+		ranked = ranked[:page.Limit]
+		last := ranked[len(ranked)-1]
+		next = pagination.Cursor{CreatedAt: now, ID: last.PostID, Score: last.Score}.Encode()
+	}
+
+	ids := make([]uint, 0, len(ranked))
+	for _, c := range ranked {
+		ids = append(ids, c.PostID)
+	}
+	posts, err := s.repo.FindPostsByIDs(ids, viewerID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to retrieve feed: %w", err)
+	}
+	byID := make(map[uint]*post.Post, len(posts))
+	for _, p := range posts {
+		byID[p.ID] = p
+	}
+
+	responses := make([]*post.PostResponse, 0, len(ranked))
+	for _, c := range ranked {
+		// post bisa terhapus di antara dua query
+		if p, ok := byID[c.PostID]; ok {
+			responses = append(responses, post.ToPostResponse(p))
+		}
+	}
+	return responses, next, nil
+}
+
+// rankedAfter membuang kandidat yang urutannya tidak setelah (score, postID).
+// Rank mengurutkan berdasarkan skor lalu post ID, keduanya menurun.
+func rankedAfter(ranked []*Candidate, score float64, postID uint) []*Candidate {
+	i := sort.Search(len(ranked), func(i int) bool {
+		c := ranked[i]
+		return c.Score < score || (c.Score == score && c.PostID < postID)
+	})
+	return ranked[i:]
+}
+
+// NewService membuat service feed. scorer nil berarti memakai WeightedScorer
+// dengan DefaultWeights.
+func NewService(repo Repository, scorer Scorer) Service {
+	if scorer == nil {
+		scorer = NewWeightedScorer(DefaultWeights)
+	}
+	return &service{repo: repo, scorer: scorer}
+}
@@ -0,0 +1,40 @@
+package feed
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRankedAfter(t *testing.T) {
+	ranked := []*Candidate{
+		{PostID: 7, Score: 3},
+		{PostID: 9, Score: 2},
+		{PostID: 4, Score: 2},
+		{PostID: 8, Score: 1},
+	}
+
+	tests := []struct {
+		name   string
+		score  float64
+		postID uint
+		want   []uint
+	}{
+		{name: "after first", score: 3, postID: 7, want: []uint{9, 4, 8}},
+		{name: "tie broken by post ID", score: 2, postID: 9, want: []uint{4, 8}},
+		{name: "after last", score: 1, postID: 8, want: []uint{}},
+		{name: "cursor between scores", score: 2.5, postID: 8, want: []uint{9, 4, 8}},
+		{name: "cursor post was deleted", score: 2, postID: 5, want: []uint{4, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []uint{}
+			for _, c := range rankedAfter(ranked, tt.score, tt.postID) {
+				got = append(got, c.PostID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rankedAfter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package follow
 
 import (
+	"errors"
+	"go-sosmed/pkg/pagination"
 	"go-sosmed/pkg/response"
 	"net/http"
 	"strconv"
@@ -35,20 +37,32 @@ func ParseFollowingID(c *gin.Context) (uint, error) {
 	return uint(id), nil
 }
 
+// Helper function to parse follow request ID from URL parameter
+func ParseRequestID(c *gin.Context) (uint, error) {
+	idParam := c.Param("request_id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
 // ===============================================
 // Controller methods
 // ===============================================
 // FollowUser godoc
 // @Summary Follow a user
-// @Description Follow another user
+// @Description Follow another user. Following a private account sends a follow request instead.
 // @Tags Follow
 // @Accept json
 // @Produce json
 // @Param following_id path int true "User ID to follow"
 // @Security BearerAuth
 // @Success 201 {object} response.SuccessResponse
+// @Success 202 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Router /api/follow/{following_id} [post]
 func (ctrl *Controller) FollowUser(c *gin.Context) {
 	followerID, ok := GetUserIDFromContext(c)
@@ -67,12 +81,20 @@ func (ctrl *Controller) FollowUser(c *gin.Context) {
 		return
 	}
 	// call service
-	err = ctrl.service.FollowUser(followerID, followingID)
+	status, err := ctrl.service.FollowUser(followerID, followingID)
 	if err != nil {
+		if errors.Is(err, ErrBlocked) {
+			response.Error(c, http.StatusForbidden, err.Error())
+			return
+		}
 		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	response.Success(c, http.StatusCreated, "Successfully followed user", nil)
+	if status == StatusPending {
+		response.Success(c, http.StatusAccepted, "Follow request sent", gin.H{"status": status})
+		return
+	}
+	response.Success(c, http.StatusCreated, "Successfully followed user", gin.H{"status": status})
 }
 
 // UnfollowUser godoc
@@ -115,8 +137,11 @@ func (ctrl *Controller) UnfollowUser(c *gin.Context) {
 // @Tags Follow
 // @Accept json
 // @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Security BearerAuth
-// @Success 200 {object} response.SuccessResponse
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/follow/me/followers [get]
@@ -127,12 +152,18 @@ func (ctrl *Controller) GetFollowers(c *gin.Context) {
 		return
 	}
 
-	followerIDs, err := ctrl.service.GetFollowers(userID)
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	followerIDs, next, err := ctrl.service.GetFollowers(userID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "Failed to get followers")
 		return
 	}
-	response.Success(c, http.StatusOK, "Followers retrieved successfully", followerIDs)
+	response.Paginated(c, http.StatusOK, "Followers retrieved successfully", followerIDs, next)
 }
 
 // GetFollowing godoc
@@ -141,8 +172,11 @@ func (ctrl *Controller) GetFollowers(c *gin.Context) {
 // @Tags Follow
 // @Accept json
 // @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Security BearerAuth
-// @Success 200 {object} response.SuccessResponse
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/follow/me/following [get]
@@ -152,12 +186,117 @@ func (ctrl *Controller) GetFollowing(c *gin.Context) {
 		response.Error(c, http.StatusUnauthorized, "user not authenticated")
 		return
 	}
-	followingIDs, err := ctrl.service.GetFollowing(userID)
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	followingIDs, next, err := ctrl.service.GetFollowing(userID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "Failed to get following")
 		return
 	}
-	response.Success(c, http.StatusOK, "Following retrieved successfully", followingIDs)
+	response.Paginated(c, http.StatusOK, "Following retrieved successfully", followingIDs, next)
+}
+
+// GetFollowRequests godoc
+// @Summary Get follow requests
+// @Description Get pending follow requests to the authenticated user's private account
+// @Tags Follow
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Security BearerAuth
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/follow/requests [get]
+func (ctrl *Controller) GetFollowRequests(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	requests, next, err := ctrl.service.GetFollowRequests(userID, page)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get follow requests")
+		return
+	}
+	response.Paginated(c, http.StatusOK, "Follow requests retrieved successfully", requests, next)
+}
+
+// ApproveFollowRequest godoc
+// @Summary Approve follow request
+// @Description Approve a pending follow request
+// @Tags Follow
+// @Produce json
+// @Param request_id path int true "Follow request ID"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/follow/requests/{request_id}/approve [post]
+func (ctrl *Controller) ApproveFollowRequest(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	requestID, err := ParseRequestID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request ID")
+		return
+	}
+	if err := ctrl.service.ApproveFollowRequest(userID, requestID); err != nil {
+		if errors.Is(err, ErrFollowRequestNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "Follow request approved", nil)
+}
+
+// RejectFollowRequest godoc
+// @Summary Reject follow request
+// @Description Reject a pending follow request
+// @Tags Follow
+// @Produce json
+// @Param request_id path int true "Follow request ID"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/follow/requests/{request_id}/reject [post]
+func (ctrl *Controller) RejectFollowRequest(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	requestID, err := ParseRequestID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request ID")
+		return
+	}
+	if err := ctrl.service.RejectFollowRequest(userID, requestID); err != nil {
+		if errors.Is(err, ErrFollowRequestNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "Follow request rejected", nil)
 }
 
 func NewController(service Service) *Controller {
@@ -1,14 +1,18 @@
 package follow
 
-import "go-sosmed/internal/user"
+import (
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
+)
 
 func ToFollowerResponse(f *Follow) *FollowerResponse {
 	return &FollowerResponse{
 		ID: f.ID,
 		Follower: user.AuthorResponse{
-			ID:       f.Follower.ID,
-			Username: f.Follower.Username,
-			Avatar:   f.Follower.Avatar,
+			ID:         f.Follower.ID,
+			Username:   f.Follower.Username,
+			Avatar:     f.Follower.Avatar,
+			IsVerified: f.Follower.VerifiedAt != nil,
 		},
 	}
 }
@@ -17,9 +21,33 @@ func ToFollowingResponse(f *Follow) *FollowingResponse {
 	return &FollowingResponse{
 		ID: f.ID,
 		Following: user.AuthorResponse{
-			ID:       f.Following.ID,
-			Username: f.Following.Username,
-			Avatar:   f.Following.Avatar,
+			ID:         f.Following.ID,
+			Username:   f.Following.Username,
+			Avatar:     f.Following.Avatar,
+			IsVerified: f.Following.VerifiedAt != nil,
 		},
 	}
 }
+
+func ToFollowRequestResponse(f *Follow) *FollowRequestResponse {
+	return &FollowRequestResponse{
+		ID: f.ID,
+		Follower: user.AuthorResponse{
+			ID:         f.Follower.ID,
+			Username:   f.Follower.Username,
+			Avatar:     f.Follower.Avatar,
+			IsVerified: f.Follower.VerifiedAt != nil,
+		},
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+// FollowCursor adalah posisi follow di daftar follower/following, diurutkan berdasarkan id
+func FollowCursor(f *Follow) pagination.Cursor {
+	return pagination.Cursor{ID: f.ID}
+}
+
+// RequestCursor adalah posisi follow request, diurutkan berdasarkan waktu request
+func RequestCursor(f *Follow) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: f.CreatedAt, ID: f.ID}
+}
@@ -1,11 +1,24 @@
 package follow
 
-import "go-sosmed/internal/user"
+import (
+	"go-sosmed/internal/user"
+	"time"
+)
+
+type StatusType = string
+
+const (
+	// follow ke akun private menunggu persetujuan pemilik akun
+	StatusPending  StatusType = user.FollowStatusPending
+	StatusAccepted StatusType = user.FollowStatusAccepted
+)
 
 type Follow struct {
-	ID          uint `gorm:"primaryKey"`
-	FollowerID  uint `gorm:"not null"`
-	FollowingID uint `gorm:"not null"`
+	ID          uint       `gorm:"primaryKey"`
+	FollowerID  uint       `gorm:"not null"`
+	FollowingID uint       `gorm:"not null"`
+	Status      StatusType `gorm:"size:20;not null;default:'accepted';index"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
 	// Relations
 	Follower  user.User `gorm:"foreignKey:FollowerID"`
 	Following user.User `gorm:"foreignKey:FollowingID"`
@@ -20,3 +33,9 @@ type FollowingResponse struct {
 	ID        uint                `json:"id"`
 	Following user.AuthorResponse `json:"following"`
 }
+
+type FollowRequestResponse struct {
+	ID        uint                `json:"id"`
+	Follower  user.AuthorResponse `json:"follower"`
+	CreatedAt time.Time           `json:"created_at"`
+}
@@ -1,13 +1,24 @@
 package follow
 
-import "gorm.io/gorm"
+import (
+	"go-sosmed/internal/timeline"
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
+
+	"gorm.io/gorm"
+)
 
 type Repository interface {
 	Create(follow *Follow) error
 	Delete(id uint) error
 	FindByUserAndFollowed(userID uint, followedID uint) (*Follow, error)
-	FindFollowersByUserID(userID uint) ([]*Follow, error)
-	FindFollowingByUserID(userID uint) ([]*Follow, error)
+	FindFollowersByUserID(userID uint, page pagination.Page) ([]*Follow, error)
+	FindFollowingByUserID(userID uint, page pagination.Page) ([]*Follow, error)
+	FindPendingRequests(userID uint, page pagination.Page) ([]*Follow, error)
+	FindPendingRequestByID(id, followingID uint) (*Follow, error)
+	UpdateStatus(id uint, status StatusType) error
+	IsPrivateUser(userID uint) (bool, error)
+	IsBlocked(userA, userB uint) (bool, error)
 }
 
 type repository struct {
@@ -15,10 +26,12 @@ type repository struct {
 }
 
 // FindFollowingByUserID implements Repository.
-func (r *repository) FindFollowingByUserID(userID uint) ([]*Follow, error) {
+func (r *repository) FindFollowingByUserID(userID uint, page pagination.Page) ([]*Follow, error) {
 	var follows []*Follow
 	if err := r.db.Preload("Following").
-		Where("follower_id = ?", userID).
+		Where("follower_id = ? AND status = ?", userID, StatusAccepted).
+		// created_at kosong untuk follow lama, urutan memakai id
+		Scopes(page.ByID("follows.id")).
 		Find(&follows).Error; err != nil {
 		return nil, err
 	}
@@ -26,24 +39,116 @@ func (r *repository) FindFollowingByUserID(userID uint) ([]*Follow, error) {
 }
 
 // FindFollowersByUserID implements Repository.
-func (r *repository) FindFollowersByUserID(userID uint) ([]*Follow, error) {
+func (r *repository) FindFollowersByUserID(userID uint, page pagination.Page) ([]*Follow, error) {
+	var follows []*Follow
+	if err := r.db.Preload("Follower").
+		Where("following_id = ? AND status = ?", userID, StatusAccepted).
+		// created_at kosong untuk follow lama, urutan memakai id
+		Scopes(page.ByID("follows.id")).
+		Find(&follows).Error; err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
+
+// FindPendingRequests implements Repository.
+func (r *repository) FindPendingRequests(userID uint, page pagination.Page) ([]*Follow, error) {
 	var follows []*Follow
 	if err := r.db.Preload("Follower").
-		Where("following_id = ?", userID).
+		Where("following_id = ? AND status = ?", userID, StatusPending).
+		Scopes(page.Newest("follows.created_at", "follows.id")).
 		Find(&follows).Error; err != nil {
 		return nil, err
 	}
 	return follows, nil
 }
 
+// FindPendingRequestByID implements Repository.
+func (r *repository) FindPendingRequestByID(id, followingID uint) (*Follow, error) {
+	var follow Follow
+	err := r.db.
+		Where("id = ? AND following_id = ? AND status = ?", id, followingID, StatusPending).
+		First(&follow).Error
+	if err != nil {
+		return nil, err
+	}
+	return &follow, nil
+}
+
+// UpdateStatus implements Repository.
+// Follow request yang disetujui langsung mengisi timeline follower dan
+// menaikkan counter follower/following kedua user.
+func (r *repository) UpdateStatus(id uint, status StatusType) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var follow Follow
+		if err := tx.First(&follow, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Follow{}).Where("id = ?", id).Update("status", status).Error; err != nil {
+			return err
+		}
+		if status == StatusAccepted && follow.Status != StatusAccepted {
+			if err := user.AdjustFollowCounts(tx, follow.FollowerID, follow.FollowingID, 1); err != nil {
+				return err
+			}
+			return timeline.Backfill(tx, follow.FollowerID, follow.FollowingID)
+		}
+		return nil
+	})
+}
+
+// IsPrivateUser implements Repository.
+// Akun yang sudah di-purge dianggap tidak ada (gorm.ErrRecordNotFound).
+func (r *repository) IsPrivateUser(userID uint) (bool, error) {
+	var u user.User
+	if err := r.db.Select("id, is_private").Where("purged_at IS NULL").First(&u, userID).Error; err != nil {
+		return false, err
+	}
+	return u.IsPrivate, nil
+}
+
+// IsBlocked implements Repository.
+func (r *repository) IsBlocked(userA, userB uint) (bool, error) {
+	return user.IsBlockedBetween(r.db, userA, userB)
+}
+
 // Create implements Repository.
+// Follow yang langsung disetujui menaikkan counter kedua user dan memasukkan
+// post terbaru user yang di-follow ke timeline follower.
 func (r *repository) Create(follow *Follow) error {
-	return r.db.Create(follow).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(follow).Error; err != nil {
+			return err
+		}
+		if follow.Status != StatusAccepted {
+			return nil
+		}
+		if err := user.AdjustFollowCounts(tx, follow.FollowerID, follow.FollowingID, 1); err != nil {
+			return err
+		}
+		return timeline.Backfill(tx, follow.FollowerID, follow.FollowingID)
+	})
 }
 
 // Delete implements Repository.
+// Post user yang di-unfollow dihapus dari timeline follower, dan counter
+// diturunkan jika follow sudah disetujui (bukan pembatalan request).
 func (r *repository) Delete(id uint) error {
-	return r.db.Delete(&Follow{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var follow Follow
+		if err := tx.First(&follow, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Follow{}, id).Error; err != nil {
+			return err
+		}
+		if follow.Status == StatusAccepted {
+			if err := user.AdjustFollowCounts(tx, follow.FollowerID, follow.FollowingID, -1); err != nil {
+				return err
+			}
+		}
+		return timeline.Prune(tx, follow.FollowerID, follow.FollowingID)
+	})
 }
 
 // FindByUserAndFollowed implements Repository.
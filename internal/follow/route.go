@@ -11,7 +11,11 @@ func SetupFollowRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
 	api := r.Group("/api/follow")
 	api.Use(middlewares.Authenticate(cfg))
 
-	api.POST("/:following_id", ctrl.FollowUser)
-	api.DELETE("/:following_id", ctrl.UnfollowUser)
+	api.POST("/:following_id", middlewares.RequireScope(middlewares.ScopeFollowsWrite), middlewares.RequireVerifiedEmail(), ctrl.FollowUser)
+	api.DELETE("/:following_id", middlewares.RequireScope(middlewares.ScopeFollowsWrite), ctrl.UnfollowUser)
+
+	api.GET("/requests", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetFollowRequests)
+	api.POST("/requests/:request_id/approve", middlewares.RequireScope(middlewares.ScopeFollowsWrite), ctrl.ApproveFollowRequest)
+	api.POST("/requests/:request_id/reject", middlewares.RequireScope(middlewares.ScopeFollowsWrite), ctrl.RejectFollowRequest)
 
 }
@@ -2,15 +2,23 @@ package follow
 
 import (
 	"errors"
+	"go-sosmed/pkg/pagination"
 
 	"gorm.io/gorm"
 )
 
+var ErrFollowRequestNotFound = errors.New("follow request not found")
+
+var ErrBlocked = errors.New("you cannot follow this user")
+
 type Service interface {
-	FollowUser(followerID, followingID uint) error
+	FollowUser(followerID, followingID uint) (StatusType, error)
 	UnfollowUser(followerID, followingID uint) error
-	GetFollowers(userID uint) ([]*FollowerResponse, error)
-	GetFollowing(userID uint) ([]*FollowingResponse, error)
+	GetFollowers(userID uint, page pagination.Page) ([]*FollowerResponse, string, error)
+	GetFollowing(userID uint, page pagination.Page) ([]*FollowingResponse, string, error)
+	GetFollowRequests(userID uint, page pagination.Page) ([]*FollowRequestResponse, string, error)
+	ApproveFollowRequest(userID, requestID uint) error
+	RejectFollowRequest(userID, requestID uint) error
 }
 
 type service struct {
@@ -18,57 +26,125 @@ type service struct {
 }
 
 // FollowUser implements Service.
-func (s *service) FollowUser(followerID uint, followingID uint) error {
+// Follow ke akun private disimpan sebagai request (pending) sampai disetujui.
+func (s *service) FollowUser(followerID uint, followingID uint) (StatusType, error) {
 
 	// Validate: cannot follow yourself
 	if followerID == followingID {
-		return errors.New("cannot follow yourself")
+		return "", errors.New("cannot follow yourself")
 	}
 
 	existing, err := s.repo.FindByUserAndFollowed(followerID, followingID)
-	// Jika data sudah ada → sudah follow
+	// Jika data sudah ada → sudah follow atau request masih menunggu
 	if err == nil && existing != nil {
-		return errors.New("already following this user")
+		if existing.Status == StatusPending {
+			return "", errors.New("follow request already sent")
+		}
+		return "", errors.New("already following this user")
 	}
 
 	// Jika error tetapi bukan "record not found" → DB error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return err
+		return "", err
+	}
+
+	blocked, err := s.repo.IsBlocked(followerID, followingID)
+	if err != nil {
+		return "", err
+	}
+	if blocked {
+		return "", ErrBlocked
+	}
+
+	isPrivate, err := s.repo.IsPrivateUser(followingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("user not found")
+		}
+		return "", err
 	}
 
 	follow := &Follow{
 		FollowerID:  followerID,
 		FollowingID: followingID,
+		Status:      StatusAccepted,
+	}
+	if isPrivate {
+		follow.Status = StatusPending
 	}
 
 	// Lebih aman: DB akan menolak jika duplicate index
-	return s.repo.Create(follow)
+	if err := s.repo.Create(follow); err != nil {
+		return "", err
+	}
+	return follow.Status, nil
+}
+
+// GetFollowRequests implements Service.
+func (s *service) GetFollowRequests(userID uint, page pagination.Page) ([]*FollowRequestResponse, string, error) {
+	follows, err := s.repo.FindPendingRequests(userID, page)
+	if err != nil {
+		return nil, "", err
+	}
+	follows, next := pagination.Trim(follows, page, RequestCursor)
+	requests := make([]*FollowRequestResponse, 0, len(follows))
+	for _, f := range follows {
+		requests = append(requests, ToFollowRequestResponse(f))
+	}
+	return requests, next, nil
+}
+
+// ApproveFollowRequest implements Service.
+func (s *service) ApproveFollowRequest(userID uint, requestID uint) error {
+	request, err := s.repo.FindPendingRequestByID(requestID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFollowRequestNotFound
+		}
+		return err
+	}
+	return s.repo.UpdateStatus(request.ID, StatusAccepted)
+}
+
+// RejectFollowRequest implements Service.
+// Request yang ditolak dihapus sehingga user bisa mengirim request lagi.
+func (s *service) RejectFollowRequest(userID uint, requestID uint) error {
+	request, err := s.repo.FindPendingRequestByID(requestID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFollowRequestNotFound
+		}
+		return err
+	}
+	return s.repo.Delete(request.ID)
 }
 
 // GetFollowers implements Service.
-func (s *service) GetFollowers(userID uint) ([]*FollowerResponse, error) {
-	post, err := s.repo.FindFollowersByUserID(userID)
+func (s *service) GetFollowers(userID uint, page pagination.Page) ([]*FollowerResponse, string, error) {
+	follows, err := s.repo.FindFollowersByUserID(userID, page)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	var followers []*FollowerResponse
-	for _, f := range post {
+	follows, next := pagination.Trim(follows, page, FollowCursor)
+	followers := make([]*FollowerResponse, 0, len(follows))
+	for _, f := range follows {
 		followers = append(followers, ToFollowerResponse(f))
 	}
-	return followers, nil
+	return followers, next, nil
 }
 
 // GetFollowing implements Service.
-func (s *service) GetFollowing(userID uint) ([]*FollowingResponse, error) {
-	follows, err := s.repo.FindFollowingByUserID(userID)
+func (s *service) GetFollowing(userID uint, page pagination.Page) ([]*FollowingResponse, string, error) {
+	follows, err := s.repo.FindFollowingByUserID(userID, page)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	var following []*FollowingResponse
+	follows, next := pagination.Trim(follows, page, FollowCursor)
+	following := make([]*FollowingResponse, 0, len(follows))
 	for _, f := range follows {
 		following = append(following, ToFollowingResponse(f))
 	}
-	return following, nil
+	return following, next, nil
 }
 
 // UnfollowUser implements Service.
@@ -81,7 +157,7 @@ func (s *service) UnfollowUser(followerID uint, followingID uint) error {
 		}
 		return err
 	}
-	// Data found, proceed to delete
+	// Data found, proceed to delete (termasuk membatalkan request yang masih pending)
 	return s.repo.Delete(existing.ID)
 }
 
@@ -1,6 +1,8 @@
 package like
 
 import (
+	"errors"
+	"go-sosmed/pkg/pagination"
 	"go-sosmed/pkg/response"
 	"net/http"
 	"strconv"
@@ -51,6 +53,7 @@ func GetUserIDFromContext(c *gin.Context) (uint, bool) {
 // @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/posts/{post_id}/like [post]
 func (ctrl *Controller) LikePost(c *gin.Context) {
@@ -66,6 +69,10 @@ func (ctrl *Controller) LikePost(c *gin.Context) {
 	}
 	err = ctrl.service.LikePost(userID, postID)
 	if err != nil {
+		if errors.Is(err, ErrBlocked) {
+			response.Error(c, http.StatusForbidden, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -118,8 +125,11 @@ func (ctrl *Controller) UnlikePost(c *gin.Context) {
 // @Tags Like
 // @Accept json
 // @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Security BearerAuth
-// @Success 200 {object} response.SuccessResponse
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/users/me/likes [get]
@@ -129,12 +139,18 @@ func (ctrl *Controller) GetPostLikedByCurrentUser(c *gin.Context) {
 		return
 	}
 
-	posts, err := ctrl.service.GetPostsLikedByUser(userID)
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	posts, next, err := ctrl.service.GetPostsLikedByUser(userID, userID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	response.Success(c, http.StatusOK, "liked posts retrieved successfully", posts)
+	response.Paginated(c, http.StatusOK, "liked posts retrieved successfully", posts, next)
 }
 
 // GetPostLikedByUser godoc
@@ -144,8 +160,10 @@ func (ctrl *Controller) GetPostLikedByCurrentUser(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param user_id path int true "User ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Security BearerAuth
-// @Success 200 {object} response.SuccessResponse
+// @Success 200 {object} response.PaginatedResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/users/{user_id}/likes [get]
@@ -155,12 +173,18 @@ func (ctrl *Controller) GetPostLikedByUser(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "invalid user ID")
 		return
 	}
-	posts, err := ctrl.service.GetPostsLikedByUser(userID)
+	viewerID, _ := GetUserIDFromContext(c)
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	posts, next, err := ctrl.service.GetPostsLikedByUser(userID, viewerID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	response.Success(c, http.StatusOK, "liked posts retrieved successfully", posts)
+	response.Paginated(c, http.StatusOK, "liked posts retrieved successfully", posts, next)
 }
 
 // IsPostLiked godoc
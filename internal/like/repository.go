@@ -2,6 +2,8 @@ package like
 
 import (
 	"go-sosmed/internal/post"
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
 
 	"gorm.io/gorm"
 )
@@ -10,7 +12,8 @@ type Repository interface {
 	Create(like *Like) error
 	Delete(id uint) error
 	GetByUserAndPost(userID uint, postID uint) (*Like, error)
-	GetPostsLikedByUser(userID uint) ([]post.Post, error)
+	GetPostsLikedByUser(userID, viewerID uint, page pagination.Page) ([]post.Post, error)
+	IsBlockedWithPostAuthor(userID, postID uint) (bool, error)
 }
 
 type repository struct {
@@ -18,18 +21,37 @@ type repository struct {
 }
 
 // Create implements Repository.
+// like_count post ikut dinaikkan dalam transaksi yang sama.
 func (r *repository) Create(like *Like) error {
-	return r.db.Create(like).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(like).Error; err != nil {
+			return err
+		}
+		return post.AdjustLikeCount(tx, like.PostID, 1)
+	})
 }
 
 // Delete implements Repository.
+// like_count post ikut diturunkan dalam transaksi yang sama.
 func (r *repository) Delete(id uint) error {
-	return r.db.Delete(&Like{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var like Like
+		if err := tx.Select("id, post_id").First(&like, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Like{}, id).Error; err != nil {
+			return err
+		}
+		return post.AdjustLikeCount(tx, like.PostID, -1)
+	})
 }
 
 // GetPostsLikedByUser implements Repository.
+// Post akun private yang tidak boleh dilihat viewerID tidak ikut ditampilkan.
 func (r *repository) GetPostsLikedByUser(
 	userID uint,
+	viewerID uint,
+	page pagination.Page,
 ) ([]post.Post, error) {
 
 	var posts []post.Post
@@ -38,12 +60,12 @@ func (r *repository) GetPostsLikedByUser(
 		Table("posts").
 		Select(`
 			posts.*,
-			(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id) AS like_count,
-			(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) AS comment_count,
-			TRUE AS is_liked
+			TRUE AS is_liked,
+			likes.id AS like_id
 		`).
 		Joins("JOIN likes ON likes.post_id = posts.id").
 		Where("likes.user_id = ? AND posts.archived = ?", userID, false).
+		Scopes(post.VisibleTo(viewerID), page.ByID("likes.id")).
 		Preload("Author").
 		Find(&posts).Error
 
@@ -54,6 +76,34 @@ func (r *repository) GetPostsLikedByUser(
 	return posts, nil
 }
 
+// IsBlockedWithPostAuthor implements Repository.
+// Draft, post terjadwal, post akun yang di-ban atau di-suspend, dan post akun
+// private atau khusus follower yang tidak boleh dilihat userID dianggap tidak
+// ada (ErrRecordNotFound).
+func (r *repository) IsBlockedWithPostAuthor(userID uint, postID uint) (bool, error) {
+	var p post.Post
+	if err := r.db.Select("id, author_id").Where("status = ?", post.StatusPublished).First(&p, postID).Error; err != nil {
+		return false, err
+	}
+	blocked, err := user.IsBlockedBetween(r.db, userID, p.AuthorID)
+	if err != nil || blocked {
+		return blocked, err
+	}
+
+	var visible int64
+	err = r.db.Model(&post.Post{}).
+		Where("posts.id = ?", postID).
+		Scopes(post.VisibleTo(userID), post.VisibleAuthors).
+		Count(&visible).Error
+	if err != nil {
+		return false, err
+	}
+	if visible == 0 {
+		return false, gorm.ErrRecordNotFound
+	}
+	return false, nil
+}
+
 // GetByUserAndPost implements Repository.
 func (r *repository) GetByUserAndPost(userID uint, postID uint) (*Like, error) {
 	var like Like
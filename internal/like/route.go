@@ -10,9 +10,9 @@ import (
 func SetupLikeRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
 	api := r.Group("/api")
 	api.Use(middlewares.Authenticate(cfg))
-	api.POST("/posts/:post_id/like", ctrl.LikePost)
-	api.DELETE("/posts/:post_id/like", ctrl.UnlikePost)
-	api.GET("/posts/:post_id/like/status", ctrl.IsPostLiked)
-	api.GET("/users/:user_id/likes", ctrl.GetPostLikedByUser)
-	api.GET("/users/me/likes", ctrl.GetPostLikedByCurrentUser)
+	api.POST("/posts/:post_id/like", middlewares.RequireScope(middlewares.ScopeLikesWrite), middlewares.RequireVerifiedEmail(), ctrl.LikePost)
+	api.DELETE("/posts/:post_id/like", middlewares.RequireScope(middlewares.ScopeLikesWrite), ctrl.UnlikePost)
+	api.GET("/posts/:post_id/like/status", middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.IsPostLiked)
+	api.GET("/users/:user_id/likes", middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.GetPostLikedByUser)
+	api.GET("/users/me/likes", middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.GetPostLikedByCurrentUser)
 }
@@ -5,28 +5,32 @@ import (
 	"fmt"
 	"go-sosmed/internal/post"
 	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
 
 	"gorm.io/gorm"
 )
 
+var ErrBlocked = errors.New("you cannot interact with this user")
+
 type Service interface {
 	LikePost(userID, postID uint) error
 	UnlikePost(userID, postID uint) error
 	IsPostLiked(userID, postID uint) (bool, error)
-	GetPostsLikedByUser(userID uint) ([]post.PostResponse, error)
+	GetPostsLikedByUser(userID, viewerID uint, page pagination.Page) ([]post.PostResponse, string, error)
 }
 
 type service struct {
 	repo Repository
 }
 
-func (s *service) GetPostsLikedByUser(userID uint) ([]post.PostResponse, error) {
-	posts, err := s.repo.GetPostsLikedByUser(userID)
+func (s *service) GetPostsLikedByUser(userID, viewerID uint, page pagination.Page) ([]post.PostResponse, string, error) {
+	posts, err := s.repo.GetPostsLikedByUser(userID, viewerID, page)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	posts, next := pagination.Trim(posts, page, post.LikedPostCursor)
 
-	var resp []post.PostResponse
+	resp := make([]post.PostResponse, 0, len(posts))
 	for _, b := range posts {
 		resp = append(resp, post.PostResponse{
 			ID:      b.ID,
@@ -34,14 +38,15 @@ func (s *service) GetPostsLikedByUser(userID uint) ([]post.PostResponse, error)
 			Content: b.Content,
 			Image:   b.Image,
 			Author: user.AuthorResponse{
-				ID:       b.Author.ID,
-				Username: b.Author.Username,
-				Avatar:   b.Author.Avatar,
+				ID:         b.Author.ID,
+				Username:   b.Author.Username,
+				Avatar:     b.Author.Avatar,
+				IsVerified: b.Author.VerifiedAt != nil,
 			},
 		})
 	}
 
-	return resp, nil
+	return resp, next, nil
 }
 
 func (s *service) IsPostLiked(userID uint, postID uint) (bool, error) {
@@ -56,6 +61,17 @@ func (s *service) IsPostLiked(userID uint, postID uint) (bool, error) {
 }
 
 func (s *service) LikePost(userID uint, postID uint) error {
+	blocked, err := s.repo.IsBlockedWithPostAuthor(userID, postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("post not found")
+		}
+		return fmt.Errorf("failed checking block status: %w", err)
+	}
+	if blocked {
+		return ErrBlocked
+	}
+
 	existing, err := s.repo.GetByUserAndPost(userID, postID)
 	if err == nil && existing != nil {
 		return fmt.Errorf("already liked")
@@ -0,0 +1,202 @@
+package mute
+
+import (
+	"errors"
+	"go-sosmed/pkg/pagination"
+	"go-sosmed/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service Service
+}
+
+// helper function to get userID from context
+func GetUserIDFromContext(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	uid, ok := userID.(uint)
+	return uid, ok
+}
+
+func ParseMuteID(c *gin.Context) (uint, error) {
+	idParam := c.Param("mute_id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// CreateMute godoc
+// @Summary Mute a user or word
+// @Description Hide posts and comments from a user (type "user", keeps following) or containing a word or phrase (type "word") from the following feed, the post list and comments. Leave duration_hours empty to mute permanently.
+// @Tags Mute
+// @Accept json
+// @Produce json
+// @Param request body MuteRequest true "Mute data"
+// @Security BearerAuth
+// @Success 201 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/mutes [post]
+func (ctrl *Controller) CreateMute(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req MuteRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mute, err := ctrl.service.CreateMute(userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidMute), errors.Is(err, ErrCannotMuteSelf), errors.Is(err, ErrTooManyMutedWords):
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrUserNotFound):
+			response.Error(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrAlreadyMuted):
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "mute created successfully", mute)
+}
+
+// GetMutes godoc
+// @Summary List mutes
+// @Description List the active muted users and words of the authenticated user
+// @Tags Mute
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Security BearerAuth
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/mutes [get]
+func (ctrl *Controller) GetMutes(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mutes, next, err := ctrl.service.GetMutes(userID, page)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Paginated(c, http.StatusOK, "mutes retrieved successfully", mutes, next)
+}
+
+// UpdateMute godoc
+// @Summary Update mute duration
+// @Description Change how long a mute lasts, counted from now. Leave duration_hours empty to make it permanent.
+// @Tags Mute
+// @Accept json
+// @Produce json
+// @Param mute_id path int true "Mute ID"
+// @Param request body UpdateMuteRequest true "Mute duration"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/mutes/{mute_id} [put]
+func (ctrl *Controller) UpdateMute(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	muteID, err := ParseMuteID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid mute ID")
+		return
+	}
+
+	var req UpdateMuteRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mute, err := ctrl.service.UpdateMute(userID, muteID, &req)
+	if err != nil {
+		if errors.Is(err, ErrMuteNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "mute updated successfully", mute)
+}
+
+// DeleteMute godoc
+// @Summary Unmute
+// @Description Remove a muted user or word
+// @Tags Mute
+// @Produce json
+// @Param mute_id path int true "Mute ID"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/mutes/{mute_id} [delete]
+func (ctrl *Controller) DeleteMute(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	muteID, err := ParseMuteID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid mute ID")
+		return
+	}
+
+	if err := ctrl.service.DeleteMute(userID, muteID); err != nil {
+		if errors.Is(err, ErrMuteNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "mute removed successfully", nil)
+}
+
+func NewController(service Service) *Controller {
+	return &Controller{service: service}
+}
@@ -0,0 +1,68 @@
+package mute
+
+import (
+	"go-sosmed/internal/user"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func ToMuteResponse(m *Mute) *MuteResponse {
+	resp := &MuteResponse{
+		ID:        m.ID,
+		Type:      m.Type,
+		Word:      m.Word,
+		ExpiresAt: m.ExpiresAt,
+		CreatedAt: m.CreatedAt,
+	}
+	if m.MutedUser != nil {
+		resp.User = &user.AuthorResponse{
+			ID:         m.MutedUser.ID,
+			Username:   m.MutedUser.Username,
+			Avatar:     m.MutedUser.Avatar,
+			IsVerified: m.MutedUser.VerifiedAt != nil,
+		}
+	}
+	return resp
+}
+
+// normalizeWord menyamakan kata yang di-mute: lowercase dan spasi dirapikan
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.Join(strings.Fields(word), " "))
+}
+
+// NotMuted menyembunyikan baris dari akun yang di-mute viewerID, serta baris yang
+// salah satu kolom teksnya mengandung kata yang di-mute. Konten milik viewer
+// sendiri tidak pernah disembunyikan. viewerID 0 (anonim) tidak difilter.
+func NotMuted(authorColumn string, textColumns []string, viewerID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db
+		}
+		now := time.Now()
+
+		db = db.Where(
+			authorColumn+` NOT IN (
+				SELECT muted_user_id FROM mutes
+				WHERE mutes.user_id = ? AND mutes.type = ?
+				AND (mutes.expires_at IS NULL OR mutes.expires_at > ?)
+			)`,
+			viewerID, TypeUser, now,
+		)
+
+		matches := make([]string, 0, len(textColumns))
+		for _, column := range textColumns {
+			matches = append(matches, "LOCATE(mutes.word, LOWER("+column+")) > 0")
+		}
+		return db.Where(
+			`(`+authorColumn+` = ? OR NOT EXISTS (
+				SELECT 1 FROM mutes
+				WHERE mutes.user_id = ? AND mutes.type = ?
+				AND (mutes.expires_at IS NULL OR mutes.expires_at > ?)
+				AND (`+strings.Join(matches, " OR ")+`)
+			))`,
+			viewerID, viewerID, TypeWord, now,
+		)
+	}
+}
@@ -0,0 +1,50 @@
+package mute
+
+import (
+	"go-sosmed/internal/user"
+	"time"
+)
+
+type MuteType = string
+
+const (
+	// akun yang di-mute tetap di-follow, hanya post dan komentarnya yang disembunyikan
+	TypeUser MuteType = "user"
+	// kata/frasa; post dan komentar yang mengandungnya disembunyikan
+	TypeWord MuteType = "word"
+)
+
+type Mute struct {
+	ID          uint     `gorm:"primaryKey"`
+	UserID      uint     `gorm:"not null;index"`
+	Type        MuteType `gorm:"size:10;not null"`
+	MutedUserID *uint    `gorm:"index"`
+	Word        string   `gorm:"size:100"` // disimpan lowercase
+	ExpiresAt   *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	// Relations
+	MutedUser *user.User `gorm:"foreignKey:MutedUserID"`
+}
+
+// MuteRequest dipakai untuk membuat mute baru. Isi user_id untuk mute akun atau
+// word untuk mute kata; duration_hours kosong berarti permanen.
+type MuteRequest struct {
+	Type          string `json:"type" form:"type" binding:"required,oneof=user word"`
+	UserID        uint   `json:"user_id" form:"user_id"`
+	Word          string `json:"word" form:"word" binding:"max=100"`
+	DurationHours int    `json:"duration_hours" form:"duration_hours" binding:"omitempty,min=1,max=8760"`
+}
+
+// UpdateMuteRequest mengubah masa berlaku mute; duration_hours 0 berarti permanen
+type UpdateMuteRequest struct {
+	DurationHours int `json:"duration_hours" form:"duration_hours" binding:"omitempty,min=1,max=8760"`
+}
+
+type MuteResponse struct {
+	ID        uint                 `json:"id"`
+	Type      string               `json:"type"`
+	User      *user.AuthorResponse `json:"user,omitempty"`
+	Word      string               `json:"word,omitempty"`
+	ExpiresAt *time.Time           `json:"expires_at,omitempty"`
+	CreatedAt time.Time            `json:"created_at"`
+}
@@ -0,0 +1,117 @@
+package mute
+
+import (
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(mute *Mute) error
+	Update(mute *Mute) error
+	Delete(id uint) error
+	FindByID(id, userID uint) (*Mute, error)
+	FindUserMute(userID, mutedUserID uint) (*Mute, error)
+	FindWordMute(userID uint, word string) (*Mute, error)
+	FindActiveByUser(userID uint, page pagination.Page) ([]*Mute, error)
+	CountActiveWords(userID uint) (int64, error)
+	UserExists(userID uint) (bool, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func active(db *gorm.DB) *gorm.DB {
+	return db.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+}
+
+// Create implements Repository.
+func (r *repository) Create(mute *Mute) error {
+	return r.db.Create(mute).Error
+}
+
+// Update implements Repository.
+func (r *repository) Update(mute *Mute) error {
+	return r.db.Save(mute).Error
+}
+
+// Delete implements Repository.
+func (r *repository) Delete(id uint) error {
+	return r.db.Delete(&Mute{}, id).Error
+}
+
+// FindByID implements Repository.
+func (r *repository) FindByID(id, userID uint) (*Mute, error) {
+	var mute Mute
+	err := r.db.
+		Preload("MutedUser").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&mute).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mute, nil
+}
+
+// FindUserMute implements Repository.
+// Termasuk mute yang sudah kedaluwarsa agar barisnya bisa dipakai ulang.
+func (r *repository) FindUserMute(userID, mutedUserID uint) (*Mute, error) {
+	var mute Mute
+	err := r.db.
+		Where("user_id = ? AND type = ? AND muted_user_id = ?", userID, TypeUser, mutedUserID).
+		First(&mute).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mute, nil
+}
+
+// FindWordMute implements Repository.
+func (r *repository) FindWordMute(userID uint, word string) (*Mute, error) {
+	var mute Mute
+	err := r.db.
+		Where("user_id = ? AND type = ? AND word = ?", userID, TypeWord, word).
+		First(&mute).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mute, nil
+}
+
+// FindActiveByUser implements Repository.
+func (r *repository) FindActiveByUser(userID uint, page pagination.Page) ([]*Mute, error) {
+	var mutes []*Mute
+	err := r.db.
+		Preload("MutedUser").
+		Where("user_id = ?", userID).
+		Scopes(active, page.Newest("mutes.created_at", "mutes.id")).
+		Find(&mutes).Error
+	if err != nil {
+		return nil, err
+	}
+	return mutes, nil
+}
+
+// CountActiveWords implements Repository.
+func (r *repository) CountActiveWords(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&Mute{}).
+		Where("user_id = ? AND type = ?", userID, TypeWord).
+		Scopes(active).
+		Count(&count).Error
+	return count, err
+}
+
+// UserExists implements Repository.
+func (r *repository) UserExists(userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&user.User{}).Where("id = ? AND purged_at IS NULL", userID).Count(&count).Error
+	return count > 0, err
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,18 @@
+package mute
+
+import (
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
+	api := r.Group("/api/users/me/mutes")
+	api.Use(middlewares.Authenticate(cfg))
+
+	api.GET("", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetMutes)
+	api.POST("", middlewares.RequireScope(middlewares.ScopeMutesWrite), ctrl.CreateMute)
+	api.PUT("/:mute_id", middlewares.RequireScope(middlewares.ScopeMutesWrite), ctrl.UpdateMute)
+	api.DELETE("/:mute_id", middlewares.RequireScope(middlewares.ScopeMutesWrite), ctrl.DeleteMute)
+}
@@ -0,0 +1,176 @@
+package mute
+
+import (
+	"errors"
+	"fmt"
+	"go-sosmed/pkg/pagination"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMuteNotFound      = errors.New("mute not found")
+	ErrAlreadyMuted      = errors.New("already muted")
+	ErrCannotMuteSelf    = errors.New("cannot mute yourself")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrInvalidMute       = errors.New("user_id is required for type user and word is required for type word")
+	ErrTooManyMutedWords = fmt.Errorf("you can mute at most %d words", maxMutedWords)
+)
+
+// batas jumlah kata aktif yang di-mute per user, karena dicek di setiap query feed
+const maxMutedWords = 100
+
+type Service interface {
+	CreateMute(userID uint, req *MuteRequest) (*MuteResponse, error)
+	GetMutes(userID uint, page pagination.Page) ([]*MuteResponse, string, error)
+	UpdateMute(userID, muteID uint, req *UpdateMuteRequest) (*MuteResponse, error)
+	DeleteMute(userID, muteID uint) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func expiresAt(durationHours int) *time.Time {
+	if durationHours <= 0 {
+		return nil
+	}
+	t := time.Now().Add(time.Duration(durationHours) * time.Hour)
+	return &t
+}
+
+func isActive(m *Mute) bool {
+	return m.ExpiresAt == nil || m.ExpiresAt.After(time.Now())
+}
+
+// CreateMute implements Service.
+// Mute lama yang sudah kedaluwarsa untuk target yang sama dipakai ulang.
+func (s *service) CreateMute(userID uint, req *MuteRequest) (*MuteResponse, error) {
+	var existing *Mute
+	var err error
+
+	switch req.Type {
+	case TypeUser:
+		if req.UserID == 0 {
+			return nil, ErrInvalidMute
+		}
+		if req.UserID == userID {
+			return nil, ErrCannotMuteSelf
+		}
+		exists, err := s.repo.UserExists(req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find user: %w", err)
+		}
+		if !exists {
+			return nil, ErrUserNotFound
+		}
+		existing, err = s.repo.FindUserMute(userID, req.UserID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	case TypeWord:
+		req.Word = normalizeWord(req.Word)
+		if req.Word == "" {
+			return nil, ErrInvalidMute
+		}
+		existing, err = s.repo.FindWordMute(userID, req.Word)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if existing == nil || !isActive(existing) {
+			count, err := s.repo.CountActiveWords(userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count muted words: %w", err)
+			}
+			if count >= maxMutedWords {
+				return nil, ErrTooManyMutedWords
+			}
+		}
+	default:
+		return nil, ErrInvalidMute
+	}
+
+	if existing != nil {
+		if isActive(existing) {
+			return nil, ErrAlreadyMuted
+		}
+		existing.ExpiresAt = expiresAt(req.DurationHours)
+		existing.CreatedAt = time.Now()
+		if err := s.repo.Update(existing); err != nil {
+			return nil, fmt.Errorf("failed to update mute: %w", err)
+		}
+		return s.getMute(userID, existing.ID)
+	}
+
+	mute := &Mute{
+		UserID:    userID,
+		Type:      req.Type,
+		ExpiresAt: expiresAt(req.DurationHours),
+	}
+	if req.Type == TypeUser {
+		mute.MutedUserID = &req.UserID
+	} else {
+		mute.Word = req.Word
+	}
+	if err := s.repo.Create(mute); err != nil {
+		return nil, fmt.Errorf("failed to create mute: %w", err)
+	}
+	return s.getMute(userID, mute.ID)
+}
+
+func (s *service) getMute(userID, muteID uint) (*MuteResponse, error) {
+	mute, err := s.repo.FindByID(muteID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mute: %w", err)
+	}
+	return ToMuteResponse(mute), nil
+}
+
+// GetMutes implements Service.
+func (s *service) GetMutes(userID uint, page pagination.Page) ([]*MuteResponse, string, error) {
+	mutes, err := s.repo.FindActiveByUser(userID, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get mutes: %w", err)
+	}
+	mutes, next := pagination.Trim(mutes, page, func(m *Mute) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: m.CreatedAt, ID: m.ID}
+	})
+	responses := make([]*MuteResponse, 0, len(mutes))
+	for _, m := range mutes {
+		responses = append(responses, ToMuteResponse(m))
+	}
+	return responses, next, nil
+}
+
+// UpdateMute implements Service.
+func (s *service) UpdateMute(userID uint, muteID uint, req *UpdateMuteRequest) (*MuteResponse, error) {
+	mute, err := s.repo.FindByID(muteID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMuteNotFound
+		}
+		return nil, err
+	}
+	mute.ExpiresAt = expiresAt(req.DurationHours)
+	if err := s.repo.Update(mute); err != nil {
+		return nil, fmt.Errorf("failed to update mute: %w", err)
+	}
+	return ToMuteResponse(mute), nil
+}
+
+// DeleteMute implements Service.
+func (s *service) DeleteMute(userID uint, muteID uint) error {
+	mute, err := s.repo.FindByID(muteID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMuteNotFound
+		}
+		return err
+	}
+	return s.repo.Delete(mute.ID)
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
@@ -1,6 +1,8 @@
 package post
 
 import (
+	"errors"
+	"go-sosmed/pkg/pagination"
 	"go-sosmed/pkg/response"
 	"net/http"
 	"strconv"
@@ -58,12 +60,15 @@ func ParseAuthorID(c *gin.Context) (uint, error) {
 // ======================================================
 // Create godoc
 // @Summary Create a new post
-// @Description Create a new post  with optional image upload
+// @Description Create a new post  with optional image upload. Posts are published immediately unless status is draft, or scheduled with a future publish_at.
 // @Tags Post
 // @Accept multipart/form-data
 // @Produce json
 // @Param title formData string true "Post title"
 // @Param content formData string true "Post content"
+// @Param visibility formData string false "Who can see the post, defaults to the author's default_post_visibility setting" Enums(public, followers)
+// @Param status formData string false "Publication status, defaults to published (or scheduled when publish_at is set)" Enums(draft, scheduled, published)
+// @Param publish_at formData string false "RFC 3339 time to publish a scheduled post"
 // @Param image formData file false "Post image"
 // @Security BearerAuth
 // @Success 201 {object} response.SuccessResponse
@@ -167,30 +172,43 @@ func (ctrl *Controller) Delete(c *gin.Context) {
 
 // GetAll godoc
 // @Summary Get all unarchived posts
-// @Description Retrieve all unarchived posts
+// @Description Retrieve all unarchived posts. Authentication is optional; posts of private accounts are only included for their approved followers.
 // @Tags Post
 // @Accept json
 // @Produce json
-// @Success 200 {object} response.SuccessResponse
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/posts [get]
 func (ctrl *Controller) GetAllUnarchived(c *gin.Context) {
-	posts, err := ctrl.service.GetAll()
+	// boleh tanpa login; userID 0 berarti anonim
+	viewerID, _ := GetUserIDFromContext(c)
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	posts, next, err := ctrl.service.GetAllUnarchived(viewerID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	response.Success(c, http.StatusOK, "posts retrieved successfully", posts)
+	response.Paginated(c, http.StatusOK, "posts retrieved successfully", posts, next)
 }
 
 // GetAllByCurrentUser godoc
 // @Summary Get all posts by current user
-// @Description Retrieve all posts (including archived) created by the authenticated user
+// @Description Retrieve all published posts (including archived) created by the authenticated user. Drafts and scheduled posts are listed by /api/posts/drafts.
 // @Tags Post
 // @Accept json
 // @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Security BearerAuth
-// @Success 200 {object} response.SuccessResponse
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/posts/author/me [get]
@@ -200,22 +218,29 @@ func (ctrl *Controller) GetAllByCurrentUser(c *gin.Context) {
 		response.Error(c, http.StatusUnauthorized, "user not authenticated")
 		return
 	}
-	posts, err := ctrl.service.GetPostsByCurrentUser(userID)
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	posts, next, err := ctrl.service.GetPostsByCurrentUser(userID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	response.Success(c, http.StatusOK, "posts retrieved successfully", posts)
+	response.Paginated(c, http.StatusOK, "posts retrieved successfully", posts, next)
 }
 
 // GetPostsByAuthor godoc
 // @Summary Get posts by specific author
-// @Description Retrieve all unarchived posts created by a specific author
+// @Description Retrieve all unarchived posts created by a specific author. Posts of a private account are only returned to its approved followers.
 // @Tags Post
 // @Accept json
 // @Produce json
 // @Param author_id path int true "Author ID"
-// @Success 200 {object} response.SuccessResponse
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} response.PaginatedResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/posts/author/{author_id} [get]
@@ -225,12 +250,18 @@ func (ctrl *Controller) GetPostsByAuthor(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "invalid author ID")
 		return
 	}
-	posts, err := ctrl.service.GetPostsByAuthor(authorID)
+	viewerID, _ := GetUserIDFromContext(c)
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	posts, next, err := ctrl.service.GetPostsByAuthor(authorID, viewerID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	response.Success(c, http.StatusOK, "posts retrieved successfully", posts)
+	response.Paginated(c, http.StatusOK, "posts retrieved successfully", posts, next)
 }
 
 // Update godoc
@@ -244,6 +275,8 @@ func (ctrl *Controller) GetPostsByAuthor(c *gin.Context) {
 // @Param content formData string false "Post content"
 // @Param archived formData boolean false "Archive status"
 // @Param edited formData boolean false "Edited status"
+// @Param status formData string false "New publication status, only for drafts and scheduled posts" Enums(draft, scheduled, published)
+// @Param publish_at formData string false "RFC 3339 time to publish a scheduled post"
 // @Param image formData file false "Post image"
 // @Security BearerAuth
 // @Success 200 {object} response.SuccessResponse
@@ -355,8 +388,11 @@ func (ctrl *Controller) Unarchive(c *gin.Context) {
 // @Tags Post
 // @Accept json
 // @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Security BearerAuth
-// @Success 200 {object} response.SuccessResponse
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/posts/following [get]
@@ -367,13 +403,19 @@ func (ctrl *Controller) GetPostsByFollowing(c *gin.Context) {
 		return
 	}
 
-	posts, err := ctrl.service.GetPostsByFollowing(userID)
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	posts, next, err := ctrl.service.GetPostsByFollowing(userID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response.Success(c, http.StatusOK, "posts retrieved successfully", posts)
+	response.Paginated(c, http.StatusOK, "posts retrieved successfully", posts, next)
 }
 
 // GetDetailByID godoc
@@ -417,8 +459,11 @@ func (ctrl *Controller) GetDetailByID(c *gin.Context) {
 // @Tags Post
 // @Accept json
 // @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Security BearerAuth
-// @Success 200 {object} response.SuccessResponse
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/posts/liked [get]
@@ -429,15 +474,93 @@ func (ctrl *Controller) GetLikedPosts(c *gin.Context) {
 		return
 	}
 
-	posts, err := ctrl.service.GetLikedPostsByUser(userID)
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	posts, next, err := ctrl.service.GetLikedPostsByUser(userID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response.Success(c, http.StatusOK, "liked posts", posts)
+	response.Paginated(c, http.StatusOK, "liked posts", posts, next)
 }
 
 func NewController(service Service) *Controller {
 	return &Controller{service: service}
 }
+
+// GetDrafts godoc
+// @Summary Get drafts of current user
+// @Description Retrieve the authenticated user's drafts and scheduled posts. Edit them with PUT /api/posts/{post_id} and publish them with PATCH /api/posts/{post_id}/publish.
+// @Tags Post
+// @Accept json
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Security BearerAuth
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/posts/drafts [get]
+func (ctrl *Controller) GetDrafts(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	posts, next, err := ctrl.service.GetDrafts(userID, page)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response.Paginated(c, http.StatusOK, "drafts retrieved successfully", posts, next)
+}
+
+// Publish godoc
+// @Summary Publish a draft
+// @Description Publish a draft or scheduled post immediately (only author can publish)
+// @Tags Post
+// @Accept json
+// @Produce json
+// @Param post_id path int true "Post ID"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/posts/{post_id}/publish [patch]
+func (ctrl *Controller) Publish(c *gin.Context) {
+	postID, err := ParsePostID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	post, err := ctrl.service.Publish(uint(postID), userID)
+	if err != nil {
+		if errors.Is(err, ErrAlreadyPublished) {
+			response.Error(c, http.StatusConflict, err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "post published successfully", post)
+}
@@ -0,0 +1,40 @@
+package post
+
+import "gorm.io/gorm"
+
+// AdjustLikeCount mengubah like_count sebuah post sebesar delta. Dipanggil di
+// dalam transaksi yang sama dengan perubahan tabel likes.
+func AdjustLikeCount(db *gorm.DB, postID uint, delta int) error {
+	return adjustCounter(db, postID, "like_count", delta)
+}
+
+// AdjustCommentCount mengubah comment_count sebuah post sebesar delta.
+// Dipanggil di dalam transaksi yang sama dengan perubahan tabel comments.
+func AdjustCommentCount(db *gorm.DB, postID uint, delta int) error {
+	return adjustCounter(db, postID, "comment_count", delta)
+}
+
+func adjustCounter(db *gorm.DB, postID uint, column string, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	// UpdateColumn agar updated_at tidak ikut berubah
+	return db.Model(&Post{}).Unscoped().
+		Where("id = ?", postID).
+		UpdateColumn(column, gorm.Expr("GREATEST("+column+" + ?, 0)", delta)).Error
+}
+
+// RecountCounters menghitung ulang like_count dan comment_count post dengan id
+// di ids dari tabel sumber. Mengembalikan jumlah post yang nilainya berubah.
+func RecountCounters(db *gorm.DB, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := db.Exec(`
+		UPDATE posts SET
+			like_count = (SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id),
+			comment_count = (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id)
+		WHERE posts.id IN ?
+	`, ids)
+	return result.RowsAffected, result.Error
+}
@@ -1,6 +1,9 @@
 package post
 
-import "go-sosmed/internal/user"
+import (
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
+)
 
 func ToPostResponse(b *Post) *PostResponse {
 	return &PostResponse{
@@ -10,15 +13,37 @@ func ToPostResponse(b *Post) *PostResponse {
 		Image:        b.Image,
 		AuthorID:     b.AuthorID,
 		Archived:     b.Archived,
+		Visibility:   b.Visibility,
 		LikeCount:    int(b.LikeCount),
 		CommentCount: int(b.CommentCount),
 		IsLiked:      b.IsLiked,
 		Edited:       b.Edited,
+		Status:       b.Status,
+		PublishAt:    b.PublishAt,
 		CreatedAt:    b.CreatedAt,
 		Author: user.AuthorResponse{
-			ID:       b.Author.ID,
-			Username: b.Author.Username,
-			Avatar:   b.Author.Avatar,
+			ID:         b.Author.ID,
+			Username:   b.Author.Username,
+			Avatar:     b.Author.Avatar,
+			IsVerified: b.Author.VerifiedAt != nil,
 		},
 	}
 }
+
+// PostCursor adalah posisi post di list yang diurutkan berdasarkan waktu dibuat
+func PostCursor(p *Post) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
+
+// LikedPostCursor adalah posisi post di list post yang di-like, diurutkan berdasarkan like terbaru
+func LikedPostCursor(p Post) pagination.Cursor {
+	return pagination.Cursor{ID: p.LikeID}
+}
+
+func toPostResponses(posts []*Post) []*PostResponse {
+	responses := make([]*PostResponse, 0, len(posts))
+	for _, p := range posts {
+		responses = append(responses, ToPostResponse(p))
+	}
+	return responses
+}
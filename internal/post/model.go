@@ -1,27 +1,54 @@
 package post
 
 import (
+	"go-sosmed/internal/setting"
 	"go-sosmed/internal/user"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+type VisibilityType = string
+
+const (
+	VisibilityPublic    VisibilityType = setting.PostVisibilityPublic
+	VisibilityFollowers VisibilityType = setting.PostVisibilityFollowers
+)
+
+type StatusType = string
+
+// Status terbit post. Hanya post published yang tampil di feed dan bisa dilihat
+// selain oleh penulisnya; post scheduled diterbitkan oleh scheduler saat publish_at tiba.
+const (
+	StatusDraft     StatusType = "draft"
+	StatusScheduled StatusType = "scheduled"
+	StatusPublished StatusType = "published"
+)
+
 type Post struct {
-	ID        uint           `gorm:"primaryKey"`
-	Title     string         `gorm:"not null"`
-	Content   string         `gorm:"type:text;not null"`
-	Image     string         `gorm:"type:text"`
-	AuthorID  uint           `gorm:"not null"`
-	Archived  bool           `gorm:"default:false"`
-	Edited    bool           `gorm:"default:false"`
+	ID       uint   `gorm:"primaryKey"`
+	Title    string `gorm:"not null"`
+	Content  string `gorm:"type:text;not null"`
+	Image    string `gorm:"type:text"`
+	AuthorID uint   `gorm:"not null"`
+	Archived bool   `gorm:"default:false"`
+	// public atau followers; default diambil dari settings penulis
+	Visibility VisibilityType `gorm:"size:16;default:'public'"`
+	Edited     bool           `gorm:"default:false"`
+	Status     StatusType     `gorm:"size:16;not null;default:'published';index:idx_posts_status_publish_at,priority:1"`
+	// waktu terbit terjadwal; setelah terbit berisi waktu terbit sebenarnya
+	PublishAt *time.Time `gorm:"index:idx_posts_status_publish_at,priority:2"`
+	// untuk draft dan post terjadwal, diset ulang ke waktu terbit saat diterbitkan
 	CreatedAt time.Time      `gorm:"autoCreateTime"`
 	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
+	// counter, hanya diubah lewat AdjustLikeCount/AdjustCommentCount dan RecountCounters
+	LikeCount    int64 `gorm:"not null;default:0;->"`
+	CommentCount int64 `gorm:"not null;default:0;->"`
 	// computed fields
-	LikeCount    int64 `gorm:"->"` // read-only
-	CommentCount int64 `gorm:"->"`
-	IsLiked      bool  `gorm:"->"`
+	IsLiked bool `gorm:"->"`
+	// id baris likes, hanya terisi pada list post yang di-like (untuk cursor)
+	LikeID uint `gorm:"->;-:migration"`
 	//relation below
 	Author user.User `gorm:"foreignKey:AuthorID"`
 }
@@ -30,6 +57,11 @@ type PostRequest struct {
 	Title   string `json:"title" form:"title" binding:"required"`
 	Content string `json:"content" form:"content" binding:"required"`
 	Image   string `json:"image"`
+	// kosong berarti memakai default_post_visibility dari settings penulis
+	Visibility string `json:"visibility" form:"visibility" binding:"omitempty,oneof=public followers"`
+	// kosong berarti langsung terbit, atau scheduled jika publish_at diisi
+	Status    string     `json:"status" form:"status" binding:"omitempty,oneof=draft scheduled published"`
+	PublishAt *time.Time `json:"publish_at" form:"publish_at"`
 }
 
 type PostResponse struct {
@@ -38,7 +70,10 @@ type PostResponse struct {
 	Content      string              `json:"content"`
 	Image        string              `json:"image"`
 	Archived     bool                `json:"archived"`
+	Visibility   string              `json:"visibility"`
 	Edited       bool                `json:"edited"`
+	Status       string              `json:"status"`
+	PublishAt    *time.Time          `json:"publish_at,omitempty"`
 	AuthorID     uint                `json:"author_id"`
 	CreatedAt    time.Time           `json:"created_at"`
 	Author       user.AuthorResponse `json:"author"`
@@ -48,8 +83,12 @@ type PostResponse struct {
 }
 
 type UpdatePostRequest struct {
-	Title    *string `json:"title" form:"title" binding:"omitempty"`
-	Content  *string `json:"content" form:"content" binding:"omitempty"`
-	Archived *bool   `json:"archived" form:"archived" binding:"omitempty"`
-	Edited   *bool   `json:"edited" form:"edited" binding:"omitempty"`
+	Title      *string `json:"title" form:"title" binding:"omitempty"`
+	Content    *string `json:"content" form:"content" binding:"omitempty"`
+	Archived   *bool   `json:"archived" form:"archived" binding:"omitempty"`
+	Visibility *string `json:"visibility" form:"visibility" binding:"omitempty,oneof=public followers"`
+	Edited     *bool   `json:"edited" form:"edited" binding:"omitempty"`
+	// hanya untuk post yang belum terbit
+	Status    *string    `json:"status" form:"status" binding:"omitempty,oneof=draft scheduled published"`
+	PublishAt *time.Time `json:"publish_at" form:"publish_at"`
 }
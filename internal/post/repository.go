@@ -1,6 +1,17 @@
 package post
 
-import "gorm.io/gorm"
+import (
+	"go-sosmed/internal/mute"
+	"go-sosmed/internal/timeline"
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type Repository interface {
 	Create(post *Post) error
@@ -8,38 +19,95 @@ type Repository interface {
 	FindDetailByID(id, userID uint) (*Post, error)
 	Update(post *Post) error
 	Delete(id uint) error
-	FindAll() ([]*Post, error)
-	FindAllUnarchived() ([]*Post, error)
+	FindAll(page pagination.Page) ([]*Post, error)
+	FindAllUnarchived(viewerID uint, page pagination.Page) ([]*Post, error)
 	Archive(id uint) error
 	Unarchive(id uint) error
-	FindByFollowing(userID uint) ([]*Post, error)
+	FindByFollowing(userID uint, page pagination.Page) ([]*Post, error)
 	FindByCurrentUser(
 		authorID uint,
 		currentUserID uint,
+		page pagination.Page,
 	) ([]*Post, error)
-	FindPostsLikedByUser(userID uint) ([]Post, error)
-	FindPostsByAuthor(authorID uint) ([]*Post, error)
+	FindPostsLikedByUser(userID uint, page pagination.Page) ([]Post, error)
+	FindPostsByAuthor(authorID, viewerID uint, page pagination.Page) ([]*Post, error)
+	FindDrafts(authorID uint, page pagination.Page) ([]*Post, error)
+	FindDueScheduled(now time.Time, limit int) ([]*Post, error)
+	Publish(id uint, at time.Time) (bool, error)
+	PublishDue(id uint, now time.Time) (bool, error)
 }
 
 type repository struct {
 	db *gorm.DB
 }
 
+// suspendedAuthors memilih ID akun yang di-ban atau sedang di-suspend
+const suspendedAuthors = "SELECT id FROM users WHERE banned_at IS NOT NULL OR suspended_until > ?"
+
+// VisibleAuthors menyembunyikan post dari akun yang di-ban atau sedang di-suspend
+func VisibleAuthors(db *gorm.DB) *gorm.DB {
+	return db.Where("posts.author_id NOT IN ("+suspendedAuthors+")", time.Now())
+}
+
+// visibleAuthorsTo sama dengan VisibleAuthors, tetapi penulis tetap bisa
+// melihat post miliknya sendiri
+func visibleAuthorsTo(viewerID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(posts.author_id = ? OR posts.author_id NOT IN ("+suspendedAuthors+"))", viewerID, time.Now())
+	}
+}
+
+// Published membatasi query ke post yang sudah terbit (bukan draft atau terjadwal)
+func Published(db *gorm.DB) *gorm.DB {
+	return db.Where("posts.status = ?", StatusPublished)
+}
+
+// VisibleTo menyembunyikan post akun private dan post khusus follower dari user
+// yang bukan follower yang sudah disetujui, serta post dari user yang saling
+// memblokir dengan viewer. Draft dan post terjadwal hanya terlihat oleh penulisnya.
+// viewerID 0 berarti pengunjung anonim.
+func VisibleTo(viewerID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(user.NotBlocked("posts.author_id", viewerID)).Where(`(
+			posts.author_id = ?
+			OR posts.status = ? AND (
+				(
+					posts.visibility = ?
+					AND posts.author_id IN (SELECT id FROM users WHERE is_private = ?)
+				)
+				OR EXISTS (
+					SELECT 1 FROM follows
+					WHERE follows.follower_id = ?
+					AND follows.following_id = posts.author_id
+					AND follows.status = ?
+				)
+			)
+		)`, viewerID, StatusPublished, VisibilityPublic, false, viewerID, user.FollowStatusAccepted)
+	}
+}
+
+// inTimeline menentukan apakah post seharusnya ada di home timeline follower
+func inTimeline(post *Post) bool {
+	return post.Status == StatusPublished && !post.Archived
+}
+
 // FindPostsByAuthor implements Repository.
-func (r *repository) FindPostsByAuthor(authorID uint) ([]*Post, error) {
+func (r *repository) FindPostsByAuthor(authorID, viewerID uint, page pagination.Page) ([]*Post, error) {
 	var posts []*Post
 
 	err := r.db.
 		Table("posts").
 		Select(`
 			posts.*,
-			(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id) AS like_count,
-			(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) AS comment_count,
-			TRUE AS is_liked
-		`).
+			EXISTS (
+				SELECT 1 FROM likes
+				WHERE likes.post_id = posts.id
+				AND likes.user_id = ?
+			) AS is_liked
+		`, viewerID).
 		Where("posts.author_id = ? AND posts.archived = ?", authorID, false).
+		Scopes(Published, VisibleTo(viewerID), page.Newest("posts.created_at", "posts.id")).
 		Preload("Author").
-		Order("posts.created_at DESC").
 		Find(&posts).Error
 
 	if err != nil {
@@ -50,21 +118,21 @@ func (r *repository) FindPostsByAuthor(authorID uint) ([]*Post, error) {
 }
 
 // GetPostsLikedByUser implements Repository.
-func (r *repository) FindPostsLikedByUser(userID uint) ([]Post, error) {
+func (r *repository) FindPostsLikedByUser(userID uint, page pagination.Page) ([]Post, error) {
 	var posts []Post
 
 	err := r.db.
 		Table("posts").
 		Select(`
 			posts.*,
-			(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id) AS like_count,
-			(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) AS comment_count,
-			TRUE AS is_liked
+			TRUE AS is_liked,
+			likes.id AS like_id
 		`).
 		Joins("JOIN likes ON likes.post_id = posts.id").
 		Where("likes.user_id = ? AND posts.archived = ?", userID, false).
+		// likes tidak punya created_at, urutan like terbaru memakai likes.id
+		Scopes(Published, VisibleTo(userID), page.ByID("likes.id")).
 		Preload("Author").
-		// Order("likes.created_at DESC").
 		Find(&posts).Error
 
 	if err != nil {
@@ -78,6 +146,7 @@ func (r *repository) FindPostsLikedByUser(userID uint) ([]Post, error) {
 func (r *repository) FindByCurrentUser(
 	authorID uint,
 	currentUserID uint,
+	page pagination.Page,
 ) ([]*Post, error) {
 
 	var posts []*Post
@@ -86,8 +155,6 @@ func (r *repository) FindByCurrentUser(
 		Table("posts").
 		Select(`
 			posts.*,
-			(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id) AS like_count,
-			(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) AS comment_count,
 			EXISTS (
 				SELECT 1 FROM likes
 				WHERE likes.post_id = posts.id
@@ -95,8 +162,8 @@ func (r *repository) FindByCurrentUser(
 			) AS is_liked
 		`, currentUserID).
 		Where("posts.author_id = ?", authorID).
+		Scopes(Published, page.Newest("posts.created_at", "posts.id")).
 		Preload("Author").
-		Order("posts.created_at DESC").
 		Find(&posts).Error
 
 	if err != nil {
@@ -107,66 +174,155 @@ func (r *repository) FindByCurrentUser(
 }
 
 // FindByFollowing implements Repository.
-func (r *repository) FindByFollowing(userID uint) ([]*Post, error) {
-	var posts []*Post
+// Post dibaca dari timeline yang sudah dimaterialisasi, lalu digabung dengan
+// post akun besar yang tidak di-fan-out (lihat package timeline).
+func (r *repository) FindByFollowing(userID uint, page pagination.Page) ([]*Post, error) {
+	var fanned []*Post
+	err := r.followingQuery(userID).
+		Scopes(
+			timeline.Entries(userID),
+			page.Newest("timeline_entries.created_at", "timeline_entries.post_id"),
+		).
+		Find(&fanned).Error
+	if err != nil {
+		return nil, err
+	}
 
-	err := r.db.
+	var large []*Post
+	err = r.followingQuery(userID).
+		Where("posts.archived = ?", false).
+		Scopes(
+			Published,
+			timeline.FromLargeAccounts(userID),
+			page.Newest("posts.created_at", "posts.id"),
+		).
+		Find(&large).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(large) == 0 {
+		return fanned, nil
+	}
+
+	posts := append(fanned, large...)
+	sort.Slice(posts, func(i, j int) bool {
+		if !posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].CreatedAt.After(posts[j].CreatedAt)
+		}
+		return posts[i].ID > posts[j].ID
+	})
+	// kedua query mengambil limit+1 baris; sisakan satu untuk deteksi halaman berikutnya
+	if len(posts) > page.Limit+1 {
+		posts = posts[:page.Limit+1]
+	}
+	return posts, nil
+}
+
+// followingQuery adalah query dasar feed following tanpa sumber post
+func (r *repository) followingQuery(userID uint) *gorm.DB {
+	return r.db.
 		Model(&Post{}).
 		Select(`
 			posts.*,
-			(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id) AS like_count,
-			(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) AS comment_count,
 			EXISTS (
 				SELECT 1 FROM likes
 				WHERE likes.post_id = posts.id
 				AND likes.user_id = ?
 			) AS is_liked
 		`, userID).
-		Joins("JOIN follows ON follows.following_id = posts.author_id").
-		Where("follows.follower_id = ? AND posts.archived = ?", userID, false).
-		Preload("Author").
-		Find(&posts).Error
-
-	if err != nil {
-		return nil, err
-	}
-
-	return posts, nil
+		Scopes(
+			VisibleAuthors,
+			user.NotBlocked("posts.author_id", userID),
+			mute.NotMuted("posts.author_id", []string{"posts.title", "posts.content"}, userID),
+		).
+		Preload("Author")
 }
 
 // FindAllUnarchived implements Repository.
-func (r *repository) FindAllUnarchived() ([]*Post, error) {
+func (r *repository) FindAllUnarchived(viewerID uint, page pagination.Page) ([]*Post, error) {
 	var posts []*Post
-	if err := r.db.Preload("Author").Where("archived = ?", false).Find(&posts).Error; err != nil {
+	err := r.db.
+		Preload("Author").
+		Where("posts.archived = ?", false).
+		Scopes(
+			Published,
+			VisibleAuthors,
+			VisibleTo(viewerID),
+			mute.NotMuted("posts.author_id", []string{"posts.title", "posts.content"}, viewerID),
+			page.Newest("posts.created_at", "posts.id"),
+		).
+		Find(&posts).Error
+	if err != nil {
 		return nil, err
 	}
 	return posts, nil
 }
 
 // Unarchive implements Repository.
+// Post yang sudah terbit dimasukkan kembali ke timeline follower.
 func (r *repository) Unarchive(id uint) error {
-	return r.db.Model(&Post{}).Where("id = ?", id).Update("archived", false).Error
+	var post Post
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id, author_id, status, created_at").First(&post, id).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Post{}).Where("id = ?", id).Update("archived", false).Error
+	})
+	if err != nil {
+		return err
+	}
+	post.Archived = false
+	if inTimeline(&post) {
+		r.fanOut(post.ID, post.AuthorID, post.CreatedAt)
+	}
+	return nil
 }
 
 // Archive implements Repository.
 func (r *repository) Archive(id uint) error {
-	return r.db.Model(&Post{}).Where("id = ?", id).Update("archived", true).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Post{}).Where("id = ?", id).Update("archived", true).Error; err != nil {
+			return err
+		}
+		return timeline.Remove(tx, id)
+	})
 }
 
 // Create implements Repository.
+// Post yang langsung terbit di-fan-out ke timeline follower setelah tersimpan.
 func (r *repository) Create(post *Post) error {
-	return r.db.Create(post).Error
+	if err := r.db.Create(post).Error; err != nil {
+		return err
+	}
+	if inTimeline(post) {
+		r.fanOut(post.ID, post.AuthorID, post.CreatedAt)
+	}
+	return nil
+}
+
+// fanOut memasukkan post ke timeline follower setelah transaksi commit. Fan-out
+// bisa mencapai MaxFanout baris sehingga tidak dijalankan di dalam transaksi
+// request; kegagalan hanya dicatat karena post sendiri sudah tersimpan.
+func (r *repository) fanOut(postID, authorID uint, createdAt time.Time) {
+	if err := timeline.Publish(r.db, postID, authorID, createdAt); err != nil {
+		log.Printf("Warning: failed to fan out post %d to timelines: %v", postID, err)
+	}
 }
 
 // Delete implements Repository.
 func (r *repository) Delete(id uint) error {
-	return r.db.Delete(&Post{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&Post{}, id).Error; err != nil {
+			return err
+		}
+		return timeline.Remove(tx, id)
+	})
 }
 
 // FindAll implements Repository.
-func (r *repository) FindAll() ([]*Post, error) {
+func (r *repository) FindAll(page pagination.Page) ([]*Post, error) {
 	var posts []*Post
-	if err := r.db.Preload("Author").Find(&posts).Error; err != nil {
+	if err := r.db.Preload("Author").Scopes(page.Newest("posts.created_at", "posts.id")).Find(&posts).Error; err != nil {
 		return nil, err
 	}
 	return posts, nil
@@ -182,6 +338,7 @@ func (r *repository) FindByID(id uint) (*Post, error) {
 }
 
 // FindDetailByID implements Repository.
+// Post dari akun yang di-ban atau di-suspend hanya terlihat oleh penulisnya.
 func (r *repository) FindDetailByID(id, userID uint) (*Post, error) {
 	var post Post
 
@@ -189,14 +346,13 @@ func (r *repository) FindDetailByID(id, userID uint) (*Post, error) {
 		Model(&Post{}).
 		Select(`
 			posts.*,
-			(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id) AS like_count,
-			(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) AS comment_count,
 			EXISTS (
 				SELECT 1 FROM likes
 				WHERE likes.post_id = posts.id
 				AND likes.user_id = ?
 			) AS is_liked
 		`, userID).
+		Scopes(VisibleTo(userID), visibleAuthorsTo(userID)).
 		Preload("Author").
 		First(&post, id).Error
 
@@ -208,8 +364,128 @@ func (r *repository) FindDetailByID(id, userID uint) (*Post, error) {
 }
 
 // Update implements Repository.
+// Timeline ikut disesuaikan jika status arsip atau status terbit berubah.
+// Baris dikunci agar tidak bentrok dengan scheduler yang menerbitkan post yang sama.
 func (r *repository) Update(post *Post) error {
-	return r.db.Save(post).Error
+	published := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var previous Post
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, archived, status").
+			First(&previous, post.ID).Error
+		if err != nil {
+			return err
+		}
+		if previous.Status == StatusPublished && post.Status != StatusPublished {
+			return ErrAlreadyPublished
+		}
+		if err := tx.Save(post).Error; err != nil {
+			return err
+		}
+		switch was, is := inTimeline(&previous), inTimeline(post); {
+		case was && !is:
+			return timeline.Remove(tx, post.ID)
+		case !was && is:
+			published = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if published {
+		r.fanOut(post.ID, post.AuthorID, post.CreatedAt)
+	}
+	return nil
+}
+
+// FindDrafts implements Repository.
+func (r *repository) FindDrafts(authorID uint, page pagination.Page) ([]*Post, error) {
+	var posts []*Post
+	err := r.db.
+		Preload("Author").
+		Where("posts.author_id = ? AND posts.status IN ?", authorID, []StatusType{StatusDraft, StatusScheduled}).
+		Scopes(page.Newest("posts.created_at", "posts.id")).
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// FindDueScheduled implements Repository.
+func (r *repository) FindDueScheduled(now time.Time, limit int) ([]*Post, error) {
+	var posts []*Post
+	err := r.db.
+		Select("id, author_id, publish_at").
+		Where("status = ? AND publish_at <= ?", StatusScheduled, now).
+		Order("publish_at ASC").
+		Limit(limit).
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// Publish implements Repository.
+// Menerbitkan draft atau post terjadwal sekarang juga. false berarti post
+// sudah terbit lebih dulu.
+func (r *repository) Publish(id uint, at time.Time) (bool, error) {
+	return r.publish(id, at, "status IN ?", []StatusType{StatusDraft, StatusScheduled})
+}
+
+// PublishDue implements Repository.
+// Update bersyarat agar post terjadwal hanya diterbitkan sekali walaupun
+// scheduler berjalan di beberapa instance. false berarti post sudah diterbitkan
+// instance lain, atau dijadwal ulang/diubah ke draft oleh penulisnya.
+func (r *repository) PublishDue(id uint, now time.Time) (bool, error) {
+	return r.publish(id, now, "status = ? AND publish_at <= ?", StatusScheduled, now)
+}
+
+// publish mengubah status post menjadi published jika kondisi terpenuhi, lalu
+// memasukkannya ke timeline follower setelah transaksi commit. created_at
+// selalu diset ke waktu terbit sebenarnya agar post yang terlambat terbit
+// (scheduler tertunda) tidak tersisip di bawah post yang sudah dibaca follower;
+// publish_at tetap menyimpan waktu jadwalnya.
+func (r *repository) publish(id uint, at time.Time, query string, args ...interface{}) (bool, error) {
+	claimed := false
+	var publishing Post
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var post Post
+		if err := tx.Select("id, author_id, archived, publish_at").First(&post, id).Error; err != nil {
+			return err
+		}
+		// diterbitkan lebih awal dari jadwal berarti publish_at ikut maju
+		publishAt := at
+		if post.PublishAt != nil && !post.PublishAt.After(at) {
+			publishAt = *post.PublishAt
+		}
+		result := tx.Model(&Post{}).
+			Where("id = ?", id).
+			Where(query, args...).
+			UpdateColumns(map[string]interface{}{
+				"status":     StatusPublished,
+				"publish_at": publishAt,
+				"created_at": at,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		claimed = true
+		publishing = post
+		return nil
+	})
+	if err != nil || !claimed {
+		return claimed, err
+	}
+	if !publishing.Archived {
+		r.fanOut(publishing.ID, publishing.AuthorID, at)
+	}
+	return true, nil
 }
 
 func NewRepository(db *gorm.DB) Repository {
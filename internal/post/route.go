@@ -10,16 +10,18 @@ import (
 func SetupPostRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
 	postGroup := r.Group("/api/posts")
 	{
-		postGroup.GET("", ctrl.GetAllUnarchived)
-		postGroup.GET("/:post_id", middlewares.Authenticate(cfg), ctrl.GetDetailByID)
-		postGroup.POST("", middlewares.Authenticate(cfg), middlewares.UploadPostImage(), ctrl.Create)
-		postGroup.PUT("/:post_id", middlewares.Authenticate(cfg), middlewares.UploadPostImage(), ctrl.Update)
-		postGroup.DELETE("/:post_id", middlewares.Authenticate(cfg), ctrl.Delete)
-		postGroup.GET("/author/:author_id", ctrl.GetPostsByAuthor)
-		postGroup.GET("/author/me", middlewares.Authenticate(cfg), ctrl.GetAllByCurrentUser)
-		postGroup.PATCH("/:post_id/archive", middlewares.Authenticate(cfg), ctrl.Archive)
-		postGroup.PATCH("/:post_id/unarchive", middlewares.Authenticate(cfg), ctrl.Unarchive)
-		postGroup.GET("/following", middlewares.Authenticate(cfg), ctrl.GetPostsByFollowing)
-		postGroup.GET("/liked/me", middlewares.Authenticate(cfg), ctrl.GetLikedPosts)
+		postGroup.GET("", middlewares.OptionalAuthenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.GetAllUnarchived)
+		postGroup.GET("/:post_id", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.GetDetailByID)
+		postGroup.POST("", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsWrite), middlewares.RequireVerifiedEmail(), middlewares.UploadPostImage(), ctrl.Create)
+		postGroup.PUT("/:post_id", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsWrite), middlewares.RequireVerifiedEmail(), middlewares.UploadPostImage(), ctrl.Update)
+		postGroup.DELETE("/:post_id", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsWrite), ctrl.Delete)
+		postGroup.GET("/author/:author_id", middlewares.OptionalAuthenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.GetPostsByAuthor)
+		postGroup.GET("/author/me", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.GetAllByCurrentUser)
+		postGroup.PATCH("/:post_id/archive", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsWrite), ctrl.Archive)
+		postGroup.PATCH("/:post_id/unarchive", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsWrite), ctrl.Unarchive)
+		postGroup.GET("/following", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.GetPostsByFollowing)
+		postGroup.GET("/drafts", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.GetDrafts)
+		postGroup.PATCH("/:post_id/publish", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsWrite), middlewares.RequireVerifiedEmail(), ctrl.Publish)
+		postGroup.GET("/liked/me", middlewares.Authenticate(cfg), middlewares.RequireScope(middlewares.ScopePostsRead), ctrl.GetLikedPosts)
 	}
 }
@@ -1,8 +1,25 @@
 package post
 
 import (
+	"errors"
 	"fmt"
+	"go-sosmed/internal/setting"
+	"go-sosmed/pkg/pagination"
+	"go-sosmed/pkg/rbac"
 	"go-sosmed/pkg/utils"
+	"log"
+	"time"
+)
+
+const (
+	// seberapa sering scheduler memeriksa post terjadwal yang sudah jatuh tempo
+	schedulerInterval  = 30 * time.Second
+	schedulerBatchSize = 100
+)
+
+var (
+	ErrAlreadyPublished = errors.New("post is already published")
+	ErrInvalidSchedule  = errors.New("scheduled posts need a publish_at in the future")
 )
 
 type Service interface {
@@ -11,32 +28,38 @@ type Service interface {
 	GetDetailByID(postID, userID uint) (*PostResponse, error)
 	Update(userID, postID uint, req *UpdatePostRequest) (*PostResponse, error)
 	Delete(postID, UserID uint, userRole string) error
-	GetAll() ([]*PostResponse, error)
-	GetAllUnarchived() ([]*PostResponse, error)
-	GetPostsByAuthor(authorID uint) ([]*PostResponse, error)
-	GetPostsByCurrentUser(authorID uint) ([]*PostResponse, error)
+	GetAll(page pagination.Page) ([]*PostResponse, string, error)
+	GetAllUnarchived(viewerID uint, page pagination.Page) ([]*PostResponse, string, error)
+	GetPostsByAuthor(authorID, viewerID uint, page pagination.Page) ([]*PostResponse, string, error)
+	GetPostsByCurrentUser(authorID uint, page pagination.Page) ([]*PostResponse, string, error)
 	Archive(postID, userID uint) error
 	Unarchive(postID, userID uint) error
-	GetPostsByFollowing(userID uint) ([]*PostResponse, error)
-	GetLikedPostsByUser(userID uint) ([]*PostResponse, error)
+	GetPostsByFollowing(userID uint, page pagination.Page) ([]*PostResponse, string, error)
+	GetLikedPostsByUser(userID uint, page pagination.Page) ([]*PostResponse, string, error)
+	GetDrafts(authorID uint, page pagination.Page) ([]*PostResponse, string, error)
+	Publish(postID, userID uint) (*PostResponse, error)
+	PublishDue() (int, error)
+	StartScheduler()
 }
 
 type service struct {
-	repo Repository
+	repo     Repository
+	settings setting.Reader
 }
 
 // GetLikedPostsByUser implements Service.
-func (s *service) GetLikedPostsByUser(userID uint) ([]*PostResponse, error) {
-	posts, err := s.repo.FindPostsLikedByUser(userID)
+func (s *service) GetLikedPostsByUser(userID uint, page pagination.Page) ([]*PostResponse, string, error) {
+	posts, err := s.repo.FindPostsLikedByUser(userID, page)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	posts, next := pagination.Trim(posts, page, LikedPostCursor)
 
-	var responses []*PostResponse
+	responses := make([]*PostResponse, 0, len(posts))
 	for _, post := range posts {
 		responses = append(responses, ToPostResponse(&post))
 	}
-	return responses, nil
+	return responses, next, nil
 }
 
 // GetDetailByID implements Service.
@@ -49,44 +72,34 @@ func (s *service) GetDetailByID(postID uint, userID uint) (*PostResponse, error)
 }
 
 // GetPostsByFollowing implements Service.
-func (s *service) GetPostsByFollowing(userID uint) ([]*PostResponse, error) {
-	posts, err := s.repo.FindByFollowing(userID)
+func (s *service) GetPostsByFollowing(userID uint, page pagination.Page) ([]*PostResponse, string, error) {
+	posts, err := s.repo.FindByFollowing(userID, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve posts: %w", err)
+		return nil, "", fmt.Errorf("failed to retrieve posts: %w", err)
 	}
-	var responses []*PostResponse
-	for _, b := range posts {
-		responses = append(responses, ToPostResponse(b))
-	}
-	return responses, nil
+	posts, next := pagination.Trim(posts, page, PostCursor)
+	return toPostResponses(posts), next, nil
 }
 
 // GetPostsByCurrentUser implements Service.
-func (s *service) GetPostsByCurrentUser(authorID uint) ([]*PostResponse, error) {
-	posts, err := s.repo.FindByCurrentUser(authorID, 0)
+func (s *service) GetPostsByCurrentUser(authorID uint, page pagination.Page) ([]*PostResponse, string, error) {
+	posts, err := s.repo.FindByCurrentUser(authorID, 0, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve posts: %w", err)
-	}
-	var responses []*PostResponse
-	for _, b := range posts {
-		if b.AuthorID == authorID {
-			responses = append(responses, ToPostResponse(b))
-		}
+		return nil, "", fmt.Errorf("failed to retrieve posts: %w", err)
 	}
-	return responses, nil
+	posts, next := pagination.Trim(posts, page, PostCursor)
+	return toPostResponses(posts), next, nil
 }
 
 // GetAllUnarchived implements Service.
-func (s *service) GetAllUnarchived() ([]*PostResponse, error) {
-	posts, err := s.repo.FindAllUnarchived()
+// viewerID 0 untuk pengunjung anonim, post akun private tidak ikut ditampilkan.
+func (s *service) GetAllUnarchived(viewerID uint, page pagination.Page) ([]*PostResponse, string, error) {
+	posts, err := s.repo.FindAllUnarchived(viewerID, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve posts: %w", err)
-	}
-	var responses []*PostResponse
-	for _, b := range posts {
-		responses = append(responses, ToPostResponse(b))
+		return nil, "", fmt.Errorf("failed to retrieve posts: %w", err)
 	}
-	return responses, nil
+	posts, next := pagination.Trim(posts, page, PostCursor)
+	return toPostResponses(posts), next, nil
 }
 
 // Archive implements Service.
@@ -121,11 +134,32 @@ func (s *service) Unarchive(postID uint, userID uint) error {
 
 // Create implements Service.
 func (s *service) Create(req *PostRequest, authorID uint) (*PostResponse, error) {
+	visibility := req.Visibility
+	if visibility == "" {
+		settings, err := s.settings.Get(authorID)
+		if err != nil {
+			return nil, err
+		}
+		visibility = settings.DefaultPostVisibility
+	}
+
+	now := time.Now()
+	status, publishAt, err := resolveStatus(req.Status, req.PublishAt, now)
+	if err != nil {
+		return nil, err
+	}
+
 	post := &Post{
-		Title:    req.Title,
-		Content:  req.Content,
-		Image:    req.Image,
-		AuthorID: authorID,
+		Title:      req.Title,
+		Content:    req.Content,
+		Image:      req.Image,
+		AuthorID:   authorID,
+		Visibility: visibility,
+		Status:     status,
+		PublishAt:  publishAt,
+	}
+	if status == StatusPublished {
+		post.CreatedAt = now
 	}
 	if err := s.repo.Create(post); err != nil {
 		return nil, err
@@ -140,7 +174,7 @@ func (s *service) Delete(postID uint, userID uint, userRole string) error {
 		return fmt.Errorf("post not found: %w", err)
 	}
 
-	if userRole != "admin" && post.AuthorID != userID {
+	if post.AuthorID != userID && !rbac.HasPermission(userRole, rbac.PermPostDeleteAny) {
 		return fmt.Errorf("unauthorized to delete this post")
 	}
 
@@ -155,31 +189,23 @@ func (s *service) Delete(postID uint, userID uint, userRole string) error {
 }
 
 // GetAll implements Service.
-func (s *service) GetAll() ([]*PostResponse, error) {
-	posts, err := s.repo.FindAll()
+func (s *service) GetAll(page pagination.Page) ([]*PostResponse, string, error) {
+	posts, err := s.repo.FindAll(page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve posts: %w", err)
+		return nil, "", fmt.Errorf("failed to retrieve posts: %w", err)
 	}
-	var responses []*PostResponse
-	for _, b := range posts {
-		responses = append(responses, ToPostResponse(b))
-	}
-	return responses, nil
+	posts, next := pagination.Trim(posts, page, PostCursor)
+	return toPostResponses(posts), next, nil
 }
 
 // GetPostsByAuthor implements Service.
-func (s *service) GetPostsByAuthor(authorID uint) ([]*PostResponse, error) {
-	posts, err := s.repo.FindPostsByAuthor(authorID)
+func (s *service) GetPostsByAuthor(authorID, viewerID uint, page pagination.Page) ([]*PostResponse, string, error) {
+	posts, err := s.repo.FindPostsByAuthor(authorID, viewerID, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve posts: %w", err)
-	}
-	var responses []*PostResponse
-	for _, b := range posts {
-		if b.AuthorID == authorID {
-			responses = append(responses, ToPostResponse(b))
-		}
+		return nil, "", fmt.Errorf("failed to retrieve posts: %w", err)
 	}
-	return responses, nil
+	posts, next := pagination.Trim(posts, page, PostCursor)
+	return toPostResponses(posts), next, nil
 }
 
 // GetByID implements Service.
@@ -207,11 +233,44 @@ func (s *service) Update(userID, postID uint, req *UpdatePostRequest) (*PostResp
 	if req.Archived != nil {
 		post.Archived = *req.Archived
 	}
+	if req.Visibility != nil {
+		post.Visibility = *req.Visibility
+	}
 
 	if post.AuthorID != userID {
 		return nil, fmt.Errorf("unauthorized to update this post")
 	}
-	post.Edited = true
+
+	// draft dan post terjadwal boleh diubah statusnya; post yang sudah terbit tidak
+	wasPublished := post.Status == StatusPublished
+	if req.Status != nil || req.PublishAt != nil {
+		if wasPublished {
+			return nil, ErrAlreadyPublished
+		}
+		status := post.Status
+		if req.Status != nil {
+			status = *req.Status
+		} else {
+			// hanya publish_at yang dikirim berarti menjadwalkan
+			status = ""
+		}
+		publishAt := post.PublishAt
+		if req.PublishAt != nil {
+			publishAt = req.PublishAt
+		}
+		now := time.Now()
+		post.Status, post.PublishAt, err = resolveStatus(status, publishAt, now)
+		if err != nil {
+			return nil, err
+		}
+		if post.Status == StatusPublished {
+			post.CreatedAt = now
+		}
+	}
+	// mengedit draft tidak menandai post sebagai edited
+	if wasPublished {
+		post.Edited = true
+	}
 
 	if err := s.repo.Update(post); err != nil {
 		return nil, fmt.Errorf("failed to update post: %w", err)
@@ -219,6 +278,115 @@ func (s *service) Update(userID, postID uint, req *UpdatePostRequest) (*PostResp
 	return ToPostResponse(post), nil
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// GetDrafts implements Service.
+// Draft dan post terjadwal milik authorID, terbaru lebih dulu.
+func (s *service) GetDrafts(authorID uint, page pagination.Page) ([]*PostResponse, string, error) {
+	posts, err := s.repo.FindDrafts(authorID, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to retrieve drafts: %w", err)
+	}
+	posts, next := pagination.Trim(posts, page, PostCursor)
+	return toPostResponses(posts), next, nil
+}
+
+// Publish implements Service.
+// Menerbitkan draft atau post terjadwal sekarang juga.
+func (s *service) Publish(postID, userID uint) (*PostResponse, error) {
+	post, err := s.repo.FindByID(postID)
+	if err != nil {
+		return nil, fmt.Errorf("post not found: %w", err)
+	}
+	if post.AuthorID != userID {
+		return nil, fmt.Errorf("unauthorized to publish this post")
+	}
+	if post.Status == StatusPublished {
+		return nil, ErrAlreadyPublished
+	}
+
+	published, err := s.repo.Publish(postID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish post: %w", err)
+	}
+	if !published {
+		// keduluan scheduler
+		return nil, ErrAlreadyPublished
+	}
+
+	post, err = s.repo.FindByID(postID)
+	if err != nil {
+		return nil, fmt.Errorf("post not found: %w", err)
+	}
+	return ToPostResponse(post), nil
+}
+
+// PublishDue implements Service.
+// Menerbitkan post terjadwal yang publish_at-nya sudah lewat. Setiap post
+// diklaim dengan update bersyarat, jadi aman dijalankan di beberapa instance.
+func (s *service) PublishDue() (int, error) {
+	posts, err := s.repo.FindDueScheduled(time.Now(), schedulerBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find scheduled posts: %w", err)
+	}
+
+	published := 0
+	for _, p := range posts {
+		ok, err := s.repo.PublishDue(p.ID, time.Now())
+		if err != nil {
+			log.Printf("Warning: failed to publish scheduled post %d: %v", p.ID, err)
+			continue
+		}
+		if ok {
+			published++
+		}
+	}
+	return published, nil
+}
+
+// StartScheduler implements Service.
+// Menjalankan PublishDue secara berkala di background. Jadwal tersimpan di
+// database, jadi post yang jatuh tempo saat server mati diterbitkan begitu
+// scheduler berjalan lagi.
+func (s *service) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+		for {
+			for {
+				published, err := s.PublishDue()
+				if err != nil {
+					log.Printf("Warning: %v", err)
+				}
+				if published < schedulerBatchSize {
+					break
+				}
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// resolveStatus menentukan status dan publish_at post dari request. Status
+// kosong berarti langsung terbit, atau scheduled jika publishAt diisi.
+func resolveStatus(status string, publishAt *time.Time, now time.Time) (StatusType, *time.Time, error) {
+	if status == "" {
+		status = StatusPublished
+		if publishAt != nil {
+			status = StatusScheduled
+		}
+	}
+	switch status {
+	case StatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return "", nil, ErrInvalidSchedule
+		}
+		return StatusScheduled, publishAt, nil
+	case StatusPublished:
+		return StatusPublished, &now, nil
+	default:
+		return StatusDraft, nil, nil
+	}
+}
+
+func NewService(repo Repository, settings setting.Reader) Service {
+	return &service{repo: repo, settings: settings}
 }
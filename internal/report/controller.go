@@ -1,6 +1,7 @@
 package report
 
 import (
+	"go-sosmed/pkg/pagination"
 	"go-sosmed/pkg/response"
 	"net/http"
 	"strconv"
@@ -116,19 +117,26 @@ func (ctrl *Controller) GetReportByID(c *gin.Context) {
 // @Tags Report
 // @Accept json
 // @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Security BearerAuth
-// @Success 200 {object} response.SuccessResponse
+// @Success 200 {object} response.PaginatedResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 403 {object} response.ErrorResponse
 // @Router /api/reports [get]
 func (ctrl *Controller) GetAllReports(c *gin.Context) {
-	resps, err := ctrl.service.GetAllReports()
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	resps, next, err := ctrl.service.GetAllReports(page)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	response.Success(c, http.StatusOK, "reports retrieved successfully", resps)
+	response.Paginated(c, http.StatusOK, "reports retrieved successfully", resps, next)
 }
 
 // UpdateReportStatus godoc
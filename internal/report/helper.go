@@ -1,5 +1,7 @@
 package report
 
+import "go-sosmed/pkg/pagination"
+
 func ToReportResponse(report *Report) *ReportResponse {
 	return &ReportResponse{
 		ID:          report.ID,
@@ -12,3 +14,7 @@ func ToReportResponse(report *Report) *ReportResponse {
 		UpdatedAt:   report.UpdatedAt,
 	}
 }
+
+func reportCursor(r *Report) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: r.CreatedAt, ID: r.ID}
+}
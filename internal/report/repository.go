@@ -1,11 +1,16 @@
 package report
 
-import "gorm.io/gorm"
+import (
+	"go-sosmed/pkg/pagination"
+
+	"gorm.io/gorm"
+)
 
 type Repository interface {
 	Create(report *Report) error
 	FindByID(id uint) (*Report, error)
-	FindAll() ([]*Report, error)
+	FindAll(page pagination.Page) ([]*Report, error)
+	FindByUserID(userID uint) ([]*Report, error)
 	Update(report *Report) error
 }
 
@@ -14,12 +19,30 @@ type repository struct {
 }
 
 // FindAll implements Repository.
-func (r *repository) FindAll() ([]*Report, error) {
+// Report terbaru ditampilkan lebih dulu.
+func (r *repository) FindAll(page pagination.Page) ([]*Report, error) {
 	var reports []*Report
 
 	err := r.db.
 		Preload("User").
 		Preload("Post").
+		Scopes(page.Newest("reports.created_at", "reports.id")).
+		Find(&reports).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// FindByUserID implements Repository.
+func (r *repository) FindByUserID(userID uint) ([]*Report, error) {
+	var reports []*Report
+
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
 		Find(&reports).Error
 
 	if err != nil {
@@ -3,6 +3,7 @@ package report
 import (
 	"go-sosmed/pkg/config"
 	"go-sosmed/pkg/middlewares"
+	"go-sosmed/pkg/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,8 +13,8 @@ func SetupRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
 	api := r.Group("/api")
 	api.Use(middlewares.Authenticate(cfg))
 
-	api.POST("/posts/:post_id/reports", ctrl.CreateReport)
-	api.GET("/reports/:report_id", middlewares.Authorize("admin"), ctrl.GetReportByID)
-	api.GET("/reports", middlewares.Authorize("admin"), ctrl.GetAllReports)
-	api.PUT("/reports/:report_id/status", middlewares.Authorize("admin"), ctrl.UpdateReportStatus)
+	api.POST("/posts/:post_id/reports", middlewares.RequireScope(middlewares.ScopeReportsWrite), middlewares.RequireVerifiedEmail(), ctrl.CreateReport)
+	api.GET("/reports/:report_id", middlewares.RequirePermission(rbac.PermReportReview), middlewares.RequireTwoFactor(), middlewares.RequireScope(middlewares.ScopeReportsRead), ctrl.GetReportByID)
+	api.GET("/reports", middlewares.RequirePermission(rbac.PermReportReview), middlewares.RequireTwoFactor(), middlewares.RequireScope(middlewares.ScopeReportsRead), ctrl.GetAllReports)
+	api.PUT("/reports/:report_id/status", middlewares.RequirePermission(rbac.PermReportReview), middlewares.RequireTwoFactor(), middlewares.RequireScope(middlewares.ScopeReportsWrite), ctrl.UpdateReportStatus)
 }
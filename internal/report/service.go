@@ -1,11 +1,14 @@
 package report
 
-import "fmt"
+import (
+	"fmt"
+	"go-sosmed/pkg/pagination"
+)
 
 type Service interface {
 	CreateReport(userID uint, postID uint, req *ReportRequest) (*ReportResponse, error)
 	GetReportByID(id uint) (*ReportResponse, error)
-	GetAllReports() ([]*ReportResponse, error)
+	GetAllReports(page pagination.Page) ([]*ReportResponse, string, error)
 	UpdateReportStatus(id uint, status string) (*ReportResponse, error)
 }
 
@@ -31,17 +34,18 @@ func (s *service) CreateReport(userID uint, postID uint, req *ReportRequest) (*R
 }
 
 // GetAllReports implements Service.
-func (s *service) GetAllReports() ([]*ReportResponse, error) {
-	report, err := s.repo.FindAll()
+func (s *service) GetAllReports(page pagination.Page) ([]*ReportResponse, string, error) {
+	report, err := s.repo.FindAll(page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get reports: %w", err)
+		return nil, "", fmt.Errorf("failed to get reports: %w", err)
 	}
+	report, next := pagination.Trim(report, page, reportCursor)
 
-	var responses []*ReportResponse
+	responses := make([]*ReportResponse, 0, len(report))
 	for _, r := range report {
 		responses = append(responses, ToReportResponse(r))
 	}
-	return responses, nil
+	return responses, next, nil
 }
 
 // GetReportByID implements Service.
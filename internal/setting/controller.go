@@ -0,0 +1,87 @@
+package setting
+
+import (
+	"go-sosmed/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service Service
+}
+
+// helper function to get userID from context
+func GetUserIDFromContext(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	uid, ok := userID.(uint)
+	return uid, ok
+}
+
+// GetSettings godoc
+// @Summary Get settings
+// @Description Get the authenticated user's settings. Settings that were never changed return their defaults.
+// @Tags Settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/settings [get]
+func (ctrl *Controller) GetSettings(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	settings, err := ctrl.service.GetSettings(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "settings retrieved successfully", settings)
+}
+
+// UpdateSettings godoc
+// @Summary Update settings
+// @Description Partially update the authenticated user's settings. Only the fields that are sent are changed.
+// @Tags Settings
+// @Accept json
+// @Produce json
+// @Param request body UpdateSettingsRequest true "Settings to change"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/settings [patch]
+func (ctrl *Controller) UpdateSettings(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	settings, err := ctrl.service.UpdateSettings(userID, &req)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "settings updated successfully", settings)
+}
+
+func NewController(service Service) *Controller {
+	return &Controller{service: service}
+}
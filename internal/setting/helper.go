@@ -0,0 +1,55 @@
+package setting
+
+func ToSettingsResponse(s *UserSettings) *SettingsResponse {
+	resp := &SettingsResponse{
+		DefaultPostVisibility: s.DefaultPostVisibility,
+		WhoCanComment:         s.WhoCanComment,
+		WhoCanMention:         s.WhoCanMention,
+		Notifications: NotificationSettings{
+			Follows:  s.NotifyFollows,
+			Likes:    s.NotifyLikes,
+			Comments: s.NotifyComments,
+			Mentions: s.NotifyMentions,
+		},
+		Language: s.Language,
+		Theme:    s.Theme,
+	}
+	if !s.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &s.UpdatedAt
+	}
+	return resp
+}
+
+// applyUpdate menyalin field yang dikirim ke settings. Nilai sudah divalidasi
+// oleh binding di request.
+func applyUpdate(s *UserSettings, req *UpdateSettingsRequest) {
+	if req.DefaultPostVisibility != nil {
+		s.DefaultPostVisibility = *req.DefaultPostVisibility
+	}
+	if req.WhoCanComment != nil {
+		s.WhoCanComment = *req.WhoCanComment
+	}
+	if req.WhoCanMention != nil {
+		s.WhoCanMention = *req.WhoCanMention
+	}
+	if n := req.Notifications; n != nil {
+		if n.Follows != nil {
+			s.NotifyFollows = *n.Follows
+		}
+		if n.Likes != nil {
+			s.NotifyLikes = *n.Likes
+		}
+		if n.Comments != nil {
+			s.NotifyComments = *n.Comments
+		}
+		if n.Mentions != nil {
+			s.NotifyMentions = *n.Mentions
+		}
+	}
+	if req.Language != nil {
+		s.Language = *req.Language
+	}
+	if req.Theme != nil {
+		s.Theme = *req.Theme
+	}
+}
@@ -0,0 +1,97 @@
+package setting
+
+import "time"
+
+// Visibilitas post. Dipakai sebagai default post baru; package post memakai
+// konstanta yang sama.
+const (
+	PostVisibilityPublic    = "public"
+	PostVisibilityFollowers = "followers" // hanya follower yang sudah disetujui
+)
+
+// Siapa yang boleh berinteraksi (komentar, mention) dengan user
+const (
+	AudienceEveryone  = "everyone"
+	AudienceFollowers = "followers"
+	AudienceNobody    = "nobody"
+)
+
+const (
+	LanguageEnglish    = "en"
+	LanguageIndonesian = "id"
+)
+
+const (
+	ThemeSystem = "system"
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+)
+
+// UserSettings adalah preferensi per user, satu baris per user. Baris baru dibuat
+// saat user pertama kali mengubah settings; sebelumnya dipakai Defaults.
+// Kolom sengaja tanpa default di database agar nilai false/kosong tidak tertimpa.
+type UserSettings struct {
+	UserID                uint   `gorm:"primaryKey;autoIncrement:false"`
+	DefaultPostVisibility string `gorm:"size:16;not null"`
+	WhoCanComment         string `gorm:"size:16;not null"`
+	WhoCanMention         string `gorm:"size:16;not null"`
+	// notifikasi
+	NotifyFollows  bool `gorm:"not null"`
+	NotifyLikes    bool `gorm:"not null"`
+	NotifyComments bool `gorm:"not null"`
+	NotifyMentions bool `gorm:"not null"`
+	// tampilan
+	Language  string    `gorm:"size:8;not null"`
+	Theme     string    `gorm:"size:8;not null"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// Defaults mengembalikan settings bawaan untuk user yang belum pernah mengubahnya
+func Defaults(userID uint) *UserSettings {
+	return &UserSettings{
+		UserID:                userID,
+		DefaultPostVisibility: PostVisibilityPublic,
+		WhoCanComment:         AudienceEveryone,
+		WhoCanMention:         AudienceEveryone,
+		NotifyFollows:         true,
+		NotifyLikes:           true,
+		NotifyComments:        true,
+		NotifyMentions:        true,
+		Language:              LanguageEnglish,
+		Theme:                 ThemeSystem,
+	}
+}
+
+// UpdateSettingsRequest dipakai oleh PATCH; field yang tidak dikirim tidak diubah
+type UpdateSettingsRequest struct {
+	DefaultPostVisibility *string                    `json:"default_post_visibility" binding:"omitempty,oneof=public followers"`
+	WhoCanComment         *string                    `json:"who_can_comment" binding:"omitempty,oneof=everyone followers nobody"`
+	WhoCanMention         *string                    `json:"who_can_mention" binding:"omitempty,oneof=everyone followers nobody"`
+	Notifications         *UpdateNotificationRequest `json:"notifications"`
+	Language              *string                    `json:"language" binding:"omitempty,oneof=en id"`
+	Theme                 *string                    `json:"theme" binding:"omitempty,oneof=system light dark"`
+}
+
+type UpdateNotificationRequest struct {
+	Follows  *bool `json:"follows"`
+	Likes    *bool `json:"likes"`
+	Comments *bool `json:"comments"`
+	Mentions *bool `json:"mentions"`
+}
+
+type SettingsResponse struct {
+	DefaultPostVisibility string               `json:"default_post_visibility"`
+	WhoCanComment         string               `json:"who_can_comment"`
+	WhoCanMention         string               `json:"who_can_mention"`
+	Notifications         NotificationSettings `json:"notifications"`
+	Language              string               `json:"language"`
+	Theme                 string               `json:"theme"`
+	UpdatedAt             *time.Time           `json:"updated_at,omitempty"`
+}
+
+type NotificationSettings struct {
+	Follows  bool `json:"follows"`
+	Likes    bool `json:"likes"`
+	Comments bool `json:"comments"`
+	Mentions bool `json:"mentions"`
+}
@@ -0,0 +1,34 @@
+package setting
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	FindByUserID(userID uint) (*UserSettings, error)
+	Save(settings *UserSettings) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// FindByUserID implements Repository.
+func (r *repository) FindByUserID(userID uint) (*UserSettings, error) {
+	var settings UserSettings
+	if err := r.db.Where("user_id = ?", userID).First(&settings).Error; err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Save implements Repository.
+// Insert atau update seluruh kolom (upsert) berdasarkan user_id.
+func (r *repository) Save(settings *UserSettings) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(settings).Error
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,16 @@
+package setting
+
+import (
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
+	api := r.Group("/api/users/me/settings")
+	api.Use(middlewares.Authenticate(cfg))
+
+	api.GET("", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetSettings)
+	api.PATCH("", middlewares.RequireScope(middlewares.ScopeProfileWrite), ctrl.UpdateSettings)
+}
@@ -0,0 +1,62 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Reader adalah akses baca settings untuk package lain (post, comment, dll) yang
+// perlu menegakkan preferensi user. User tanpa settings tersimpan mendapat Defaults.
+type Reader interface {
+	Get(userID uint) (*UserSettings, error)
+}
+
+type Service interface {
+	Reader
+	GetSettings(userID uint) (*SettingsResponse, error)
+	UpdateSettings(userID uint, req *UpdateSettingsRequest) (*SettingsResponse, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// Get implements Reader.
+func (s *service) Get(userID uint) (*UserSettings, error) {
+	settings, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Defaults(userID), nil
+		}
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	return settings, nil
+}
+
+// GetSettings implements Service.
+func (s *service) GetSettings(userID uint) (*SettingsResponse, error) {
+	settings, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	return ToSettingsResponse(settings), nil
+}
+
+// UpdateSettings implements Service.
+func (s *service) UpdateSettings(userID uint, req *UpdateSettingsRequest) (*SettingsResponse, error) {
+	settings, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	applyUpdate(settings, req)
+	if err := s.repo.Save(settings); err != nil {
+		return nil, fmt.Errorf("failed to update settings: %w", err)
+	}
+	return ToSettingsResponse(settings), nil
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
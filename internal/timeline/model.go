@@ -0,0 +1,23 @@
+package timeline
+
+import "time"
+
+// TimelineEntry adalah satu post di home timeline seorang user. Baris dibuat
+// saat post terbit (fan-out-on-write) sehingga feed following cukup dibaca
+// dengan range scan pada (user_id, created_at, post_id).
+type TimelineEntry struct {
+	UserID   uint `gorm:"primaryKey;autoIncrement:false;index:idx_timeline_feed,priority:1;index:idx_timeline_author,priority:1"`
+	PostID   uint `gorm:"primaryKey;autoIncrement:false;index"`
+	AuthorID uint `gorm:"not null;index:idx_timeline_author,priority:2"`
+	// waktu post dibuat, bukan waktu entry dibuat, agar urutan sama dengan cursor post
+	CreatedAt time.Time `gorm:"not null;index:idx_timeline_feed,priority:2"`
+}
+
+// TimelineLargeAccount menandai akun dengan follower lebih dari MaxFanout.
+// Post akun ini tidak di-fan-out, tetapi dibaca langsung saat feed diminta
+// (fan-out-on-read).
+type TimelineLargeAccount struct {
+	UserID         uint `gorm:"primaryKey;autoIncrement:false"`
+	FollowersCount int64
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
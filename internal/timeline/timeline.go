@@ -0,0 +1,186 @@
+// Package timeline memelihara home timeline yang dimaterialisasi (fan-out-on-write).
+// Fungsi di sini menerima *gorm.DB agar bisa dipanggil di dalam transaksi
+// repository domain lain (follow, block, user), kecuali Publish yang dijalankan
+// setelah transaksi post commit.
+package timeline
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// akun dengan follower lebih dari ini memakai fan-out-on-read
+	MaxFanout = 10000
+	// jumlah post terbaru yang dimasukkan ke timeline saat mulai follow
+	BackfillLimit = 50
+	// jumlah follower yang diisi timeline-nya per INSERT saat fan-out
+	FanoutBatchSize = 1000
+
+	statusAccepted  = "accepted"
+	statusPublished = "published"
+)
+
+// Publish memasukkan post ke timeline semua follower penulis, per batch
+// FanoutBatchSize follower agar tidak ada satu statement atau transaksi besar.
+// Dipanggil setelah post tersimpan, di luar transaksi request.
+// Jika follower terlalu banyak (users.followers_count), penulis ditandai sebagai
+// akun besar dan post dibaca lewat FromLargeAccounts. Akun yang turun di bawah
+// batas di-backfill ulang agar post selama masa akun besar tetap muncul.
+func Publish(db *gorm.DB, postID, authorID uint, createdAt time.Time) error {
+	var followers int64
+	err := db.Table("users").Select("followers_count").Where("id = ?", authorID).Scan(&followers).Error
+	if err != nil {
+		return err
+	}
+
+	if followers > MaxFanout {
+		return db.Clauses(clause.OnConflict{UpdateAll: true}).
+			Create(&TimelineLargeAccount{UserID: authorID, FollowersCount: followers}).Error
+	}
+
+	result := db.Where("user_id = ?", authorID).Delete(&TimelineLargeAccount{})
+	if result.Error != nil {
+		return result.Error
+	}
+	wasLarge := result.RowsAffected > 0
+
+	var lastID uint
+	for {
+		var batch []struct {
+			ID         uint
+			FollowerID uint
+		}
+		err := db.Table("follows").
+			Select("id, follower_id").
+			Where("following_id = ? AND status = ? AND id > ?", authorID, statusAccepted, lastID).
+			Order("id ASC").
+			Limit(FanoutBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		followerIDs := make([]uint, 0, len(batch))
+		entries := make([]TimelineEntry, 0, len(batch))
+		for _, f := range batch {
+			followerIDs = append(followerIDs, f.FollowerID)
+			entries = append(entries, TimelineEntry{UserID: f.FollowerID, PostID: postID, AuthorID: authorID, CreatedAt: createdAt})
+		}
+		if wasLarge {
+			err := db.Exec(`
+				INSERT IGNORE INTO timeline_entries (user_id, post_id, author_id, created_at)
+				SELECT follows.follower_id, recent.id, recent.author_id, recent.created_at
+				FROM follows
+				JOIN (
+					SELECT id, author_id, created_at FROM posts
+					WHERE author_id = ? AND status = ? AND archived = ? AND deleted_at IS NULL
+					ORDER BY created_at DESC LIMIT ?
+				) AS recent ON recent.author_id = follows.following_id
+				WHERE follows.following_id = ? AND follows.status = ? AND follows.follower_id IN ?
+			`, authorID, statusPublished, false, BackfillLimit, authorID, statusAccepted, followerIDs).Error
+			if err != nil {
+				return err
+			}
+		}
+		if err := db.Clauses(clause.Insert{Modifier: "IGNORE"}).Create(&entries).Error; err != nil {
+			return err
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+// Remove menghapus post dari semua timeline (post diarsipkan atau dihapus)
+func Remove(db *gorm.DB, postID uint) error {
+	return db.Where("post_id = ?", postID).Delete(&TimelineEntry{}).Error
+}
+
+// Backfill memasukkan post terbaru followingID ke timeline followerID setelah
+// follow disetujui. Akun besar dilewati karena dibaca saat feed diminta.
+func Backfill(db *gorm.DB, followerID, followingID uint) error {
+	return db.Exec(`
+		INSERT IGNORE INTO timeline_entries (user_id, post_id, author_id, created_at)
+		SELECT ?, id, author_id, created_at FROM posts
+		WHERE author_id = ? AND status = ? AND archived = ? AND deleted_at IS NULL
+		AND author_id NOT IN (SELECT user_id FROM timeline_large_accounts)
+		ORDER BY created_at DESC LIMIT ?
+	`, followerID, followingID, statusPublished, false, BackfillLimit).Error
+}
+
+// Prune menghapus post followingID dari timeline followerID (unfollow atau block)
+func Prune(db *gorm.DB, followerID, followingID uint) error {
+	return db.Where("user_id = ? AND author_id = ?", followerID, followingID).Delete(&TimelineEntry{}).Error
+}
+
+// Entries membatasi query posts ke isi home timeline userID
+func Entries(userID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Joins("JOIN timeline_entries ON timeline_entries.post_id = posts.id AND timeline_entries.user_id = ?", userID)
+	}
+}
+
+// FromLargeAccounts membatasi query posts ke post akun besar yang di-follow userID
+func FromLargeAccounts(userID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`posts.author_id IN (
+			SELECT follows.following_id FROM follows
+			JOIN timeline_large_accounts ON timeline_large_accounts.user_id = follows.following_id
+			WHERE follows.follower_id = ? AND follows.status = ?
+		)`, userID, statusAccepted)
+	}
+}
+
+// StartBackfill mengisi timeline dari follow yang sudah ada ketika tabel
+// timeline masih kosong, misalnya pertama kali fitur ini di-deploy.
+func StartBackfill(db *gorm.DB) {
+	var exists int64
+	if err := db.Model(&TimelineEntry{}).Limit(1).Count(&exists).Error; err != nil {
+		log.Printf("Warning: failed to check timeline entries: %v", err)
+		return
+	}
+	if exists > 0 {
+		return
+	}
+
+	go func() {
+		type pair struct {
+			ID          uint
+			FollowerID  uint
+			FollowingID uint
+		}
+		var lastID uint
+		filled := 0
+		for {
+			var pairs []pair
+			err := db.Table("follows").
+				Select("id, follower_id, following_id").
+				Where("id > ? AND status = ?", lastID, statusAccepted).
+				Order("id ASC").
+				Limit(500).
+				Find(&pairs).Error
+			if err != nil {
+				log.Printf("Warning: timeline backfill stopped: %v", err)
+				return
+			}
+			if len(pairs) == 0 {
+				break
+			}
+			for _, p := range pairs {
+				if err := Backfill(db, p.FollowerID, p.FollowingID); err != nil {
+					log.Printf("Warning: failed to backfill timeline of user %d: %v", p.FollowerID, err)
+				}
+				lastID = p.ID
+			}
+			filled += len(pairs)
+		}
+		if filled > 0 {
+			log.Printf("Timeline backfill finished for %d follows", filled)
+		}
+	}()
+}
@@ -0,0 +1,231 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"go-sosmed/pkg/jwtkeys"
+	"go-sosmed/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service Service
+}
+
+// helper function to get userID from context
+func GetUserIDFromContext(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	uid, ok := userID.(uint)
+	return uid, ok
+}
+
+// helper function to get current session ID from context
+func GetSessionIDFromContext(c *gin.Context) uint {
+	sessionID, exists := c.Get("sessionID")
+	if !exists {
+		return 0
+	}
+	sid, _ := sessionID.(uint)
+	return sid
+}
+
+func ParseSessionID(c *gin.Context) (uint, error) {
+	idParam := c.Param("session_id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// GetSessions godoc
+// @Summary List active sessions
+// @Description List the devices where the current user is signed in
+// @Tags Session
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/sessions [get]
+func (ctrl *Controller) GetSessions(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	sessions, err := ctrl.service.GetActiveSessions(userID, GetSessionIDFromContext(c))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "sessions retrieved successfully", sessions)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Sign out a device by revoking its session and refresh tokens
+// @Tags Session
+// @Produce json
+// @Param session_id path int true "Session ID"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/users/me/sessions/{session_id} [delete]
+func (ctrl *Controller) RevokeSession(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	sessionID, err := ParseSessionID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid session ID")
+		return
+	}
+
+	if err := ctrl.service.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "session revoked successfully", nil)
+}
+
+func ParseTokenID(c *gin.Context) (uint, error) {
+	idParam := c.Param("token_id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// CreatePersonalAccessToken godoc
+// @Summary Create personal access token
+// @Description Create a named, scoped API token for scripts. The token is only returned once.
+// @Tags Token
+// @Accept json
+// @Produce json
+// @Param data body CreatePersonalAccessTokenRequest true "Token name, scopes and optional expiry"
+// @Security BearerAuth
+// @Success 201 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /api/users/me/tokens [post]
+func (ctrl *Controller) CreatePersonalAccessToken(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req CreatePersonalAccessTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	pat, err := ctrl.service.CreatePersonalAccessToken(userID, &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "personal access token created successfully", pat)
+}
+
+// GetPersonalAccessTokens godoc
+// @Summary List personal access tokens
+// @Description List the active personal access tokens of the current user
+// @Tags Token
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/tokens [get]
+func (ctrl *Controller) GetPersonalAccessTokens(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	tokens, err := ctrl.service.GetPersonalAccessTokens(userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "personal access tokens retrieved successfully", tokens)
+}
+
+// RevokePersonalAccessToken godoc
+// @Summary Revoke personal access token
+// @Description Revoke a personal access token so it can no longer be used
+// @Tags Token
+// @Produce json
+// @Param token_id path int true "Token ID"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/users/me/tokens/{token_id} [delete]
+func (ctrl *Controller) RevokePersonalAccessToken(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	tokenID, err := ParseTokenID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid token ID")
+		return
+	}
+
+	if err := ctrl.service.RevokePersonalAccessToken(userID, tokenID); err != nil {
+		if errors.Is(err, ErrPersonalAccessTokenNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "personal access token revoked successfully", nil)
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens issued by this API
+// @Tags Token
+// @Produce json
+// @Success 200 {object} jwtkeys.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (ctrl *Controller) JWKS(c *gin.Context) {
+	// format standar JWKS, tidak dibungkus response.Success
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwtkeys.JWKSMaxAge.Seconds())))
+	c.JSON(http.StatusOK, jwtkeys.Get().JWKS())
+}
+
+func NewController(service Service) *Controller {
+	return &Controller{service: service}
+}
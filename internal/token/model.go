@@ -0,0 +1,93 @@
+package token
+
+import "time"
+
+// RefreshToken menyimpan refresh token dalam bentuk hash.
+// Setiap rotasi membuat token baru dalam family yang sama, sehingga
+// pemakaian ulang token lama bisa dideteksi dan seluruh family dicabut.
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey"`
+	UserID       uint       `gorm:"not null;index"`
+	SessionID    uint       `gorm:"index"`
+	FamilyID     string     `gorm:"size:64;not null;index"`
+	TokenHash    string     `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt    time.Time  `gorm:"not null"`
+	RevokedAt    *time.Time `gorm:"index"`
+	ReplacedByID *uint
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// Session mewakili satu login di satu perangkat.
+// Access token membawa ID session (sid) sehingga session bisa dicabut kapan saja.
+type Session struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"not null;index"`
+	UserAgent  string     `gorm:"type:text"`
+	Device     string     `gorm:"size:100"`
+	IPAddress  string     `gorm:"size:45"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	LastSeenAt time.Time  `gorm:"not null"`
+	ExpiresAt  time.Time  `gorm:"not null"`
+	RevokedAt  *time.Time `gorm:"index"`
+}
+
+// RevokedToken adalah denylist access token (berdasarkan jti) yang dicabut
+// sebelum masa berlakunya habis, misalnya saat logout.
+type RevokedToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	JTI       string    `gorm:"column:jti;size:64;not null;uniqueIndex"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// PersonalAccessToken adalah token API bernama untuk script dan integrasi.
+// Token mentah hanya ditampilkan sekali saat dibuat; yang disimpan hanya hash-nya.
+type PersonalAccessToken struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"not null;index"`
+	Name        string `gorm:"size:100;not null"`
+	TokenPrefix string `gorm:"size:32;not null"` // beberapa karakter awal untuk dikenali user
+	TokenHash   string `gorm:"size:64;not null;uniqueIndex"`
+	Scopes      string `gorm:"size:255;not null"` // dipisah spasi
+	LastUsedAt  *time.Time
+	ExpiresAt   *time.Time
+	RevokedAt   *time.Time `gorm:"index"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
+
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name" form:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" form:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" form:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+type PersonalAccessTokenResponse struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreatedPersonalAccessTokenResponse berisi token mentah, hanya dikembalikan sekali saat dibuat
+type CreatedPersonalAccessTokenResponse struct {
+	Token string `json:"token"`
+	PersonalAccessTokenResponse
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token"`
+}
+
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
@@ -0,0 +1,211 @@
+package token
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	CreateSession(session *Session) error
+	FindSessionByID(id uint) (*Session, error)
+	FindActiveSessionsByUserID(userID uint) ([]*Session, error)
+	TouchSession(id uint, expiresAt time.Time) error
+	RevokeSession(id uint) error
+	RevokeSessionsByUserID(userID uint) error
+	RevokeOtherSessions(userID, keepSessionID uint) error
+	CreateRefreshToken(token *RefreshToken) error
+	FindRefreshTokenByHash(hash string) (*RefreshToken, error)
+	RotateRefreshToken(current *RefreshToken, next *RefreshToken) error
+	RevokeFamily(familyID string) error
+	RevokeAllByUserID(userID uint) error
+	CreateRevokedToken(revoked *RevokedToken) error
+	DeleteExpiredRevokedTokens() error
+	CreatePersonalAccessToken(pat *PersonalAccessToken) error
+	FindActivePersonalAccessTokensByUserID(userID uint) ([]*PersonalAccessToken, error)
+	CountActivePersonalAccessTokens(userID uint) (int64, error)
+	RevokePersonalAccessToken(userID, id uint) (bool, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// CreateSession implements Repository.
+func (r *repository) CreateSession(session *Session) error {
+	return r.db.Create(session).Error
+}
+
+// FindSessionByID implements Repository.
+func (r *repository) FindSessionByID(id uint) (*Session, error) {
+	var session Session
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindActiveSessionsByUserID implements Repository.
+func (r *repository) FindActiveSessionsByUserID(userID uint) ([]*Session, error) {
+	var sessions []*Session
+	err := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// TouchSession implements Repository.
+func (r *repository) TouchSession(id uint, expiresAt time.Time) error {
+	return r.db.Model(&Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_seen_at": time.Now(),
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+// RevokeSession implements Repository.
+// Session dan semua refresh token miliknya dicabut sekaligus.
+func (r *repository) RevokeSession(id uint) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Session{}).
+			Where("id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&RefreshToken{}).
+			Where("session_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error
+	})
+}
+
+// RevokeSessionsByUserID implements Repository.
+func (r *repository) RevokeSessionsByUserID(userID uint) error {
+	return r.db.Model(&Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeOtherSessions implements Repository.
+// Semua session user kecuali keepSessionID (beserta refresh token-nya) dicabut.
+func (r *repository) RevokeOtherSessions(userID, keepSessionID uint) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Session{}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepSessionID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&RefreshToken{}).
+			Where("user_id = ? AND session_id <> ? AND revoked_at IS NULL", userID, keepSessionID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// CreateRefreshToken implements Repository.
+func (r *repository) CreateRefreshToken(token *RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindRefreshTokenByHash implements Repository.
+func (r *repository) FindRefreshTokenByHash(hash string) (*RefreshToken, error) {
+	var token RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken implements Repository.
+// Token lama hanya bisa diganti sekali; jika sudah dicabut oleh request lain
+// (race atau reuse) maka rotasi dibatalkan.
+func (r *repository) RotateRefreshToken(current *RefreshToken, next *RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": next.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+		return nil
+	})
+}
+
+// RevokeFamily implements Repository.
+func (r *repository) RevokeFamily(familyID string) error {
+	return r.db.Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllByUserID implements Repository.
+func (r *repository) RevokeAllByUserID(userID uint) error {
+	return r.db.Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// CreateRevokedToken implements Repository.
+func (r *repository) CreateRevokedToken(revoked *RevokedToken) error {
+	return r.db.Create(revoked).Error
+}
+
+// DeleteExpiredRevokedTokens implements Repository.
+func (r *repository) DeleteExpiredRevokedTokens() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&RevokedToken{}).Error
+}
+
+// CreatePersonalAccessToken implements Repository.
+func (r *repository) CreatePersonalAccessToken(pat *PersonalAccessToken) error {
+	return r.db.Create(pat).Error
+}
+
+// FindActivePersonalAccessTokensByUserID implements Repository.
+func (r *repository) FindActivePersonalAccessTokensByUserID(userID uint) ([]*PersonalAccessToken, error) {
+	var tokens []*PersonalAccessToken
+	err := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// CountActivePersonalAccessTokens implements Repository.
+func (r *repository) CountActivePersonalAccessTokens(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Count(&count).Error
+	return count, err
+}
+
+// RevokePersonalAccessToken implements Repository.
+// Mengembalikan false jika token tidak ada, milik user lain, atau sudah dicabut.
+func (r *repository) RevokePersonalAccessToken(userID, id uint) (bool, error) {
+	result := r.db.Model(&PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,23 @@
+package token
+
+import (
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
+	r.GET("/.well-known/jwks.json", ctrl.JWKS)
+
+	api := r.Group("/api/users/me")
+	// personal access token tidak boleh mengelola session maupun token lain
+	api.Use(middlewares.Authenticate(cfg), middlewares.RequireSessionAuth())
+
+	api.GET("/sessions", ctrl.GetSessions)
+	api.DELETE("/sessions/:session_id", ctrl.RevokeSession)
+
+	api.POST("/tokens", ctrl.CreatePersonalAccessToken)
+	api.GET("/tokens", ctrl.GetPersonalAccessTokens)
+	api.DELETE("/tokens/:token_id", ctrl.RevokePersonalAccessToken)
+}
@@ -0,0 +1,335 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/middlewares"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+var ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+
+// batas jumlah personal access token aktif per user
+const maxPersonalAccessTokens = 50
+
+type Service interface {
+	CreateSession(userID uint, userAgent, ipAddress string) (*Session, error)
+	GetActiveSessions(userID, currentSessionID uint) ([]*SessionResponse, error)
+	RevokeSession(userID, sessionID uint) error
+	IssueRefreshToken(userID, sessionID uint) (string, *RefreshToken, error)
+	RotateRefreshToken(raw string) (string, *RefreshToken, error)
+	RevokeRefreshToken(raw string) error
+	RevokeAllForUser(userID uint) error
+	RevokeOtherSessions(userID, keepSessionID uint) error
+	RevokeAccessToken(jti string, userID uint, expiresAt time.Time) error
+	CreatePersonalAccessToken(userID uint, req *CreatePersonalAccessTokenRequest) (*CreatedPersonalAccessTokenResponse, error)
+	GetPersonalAccessTokens(userID uint) ([]*PersonalAccessTokenResponse, error)
+	RevokePersonalAccessToken(userID, tokenID uint) error
+}
+
+type service struct {
+	repo Repository
+	cfg  *config.Config
+}
+
+func (s *service) refreshDuration() time.Duration {
+	duration, err := time.ParseDuration(s.cfg.JWTRefreshExpires)
+	if err != nil {
+		duration = 720 * time.Hour // default 30 days
+	}
+	return duration
+}
+
+func (s *service) newRefreshToken(userID, sessionID uint, familyID string) (string, *RefreshToken, error) {
+	raw, err := GenerateRandomString(32)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return raw, &RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		FamilyID:  familyID,
+		TokenHash: HashToken(raw),
+		ExpiresAt: time.Now().Add(s.refreshDuration()),
+	}, nil
+}
+
+// CreateSession implements Service.
+func (s *service) CreateSession(userID uint, userAgent, ipAddress string) (*Session, error) {
+	now := time.Now()
+	session := &Session{
+		UserID:     userID,
+		UserAgent:  userAgent,
+		Device:     DescribeDevice(userAgent),
+		IPAddress:  ipAddress,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.refreshDuration()),
+	}
+	if err := s.repo.CreateSession(session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return session, nil
+}
+
+// GetActiveSessions implements Service.
+func (s *service) GetActiveSessions(userID, currentSessionID uint) ([]*SessionResponse, error) {
+	sessions, err := s.repo.FindActiveSessionsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	responses := []*SessionResponse{}
+	for _, session := range sessions {
+		responses = append(responses, ToSessionResponse(session, currentSessionID))
+	}
+	return responses, nil
+}
+
+// RevokeSession implements Service.
+func (s *service) RevokeSession(userID, sessionID uint) error {
+	session, err := s.repo.FindSessionByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	// session milik user lain diperlakukan seperti tidak ada
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	if err := s.repo.RevokeSession(session.ID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// IssueRefreshToken implements Service.
+// Membuat refresh token pertama dari sebuah family baru (dipakai saat login).
+func (s *service) IssueRefreshToken(userID, sessionID uint) (string, *RefreshToken, error) {
+	familyID, err := GenerateRandomString(24)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token family: %w", err)
+	}
+	raw, token, err := s.newRefreshToken(userID, sessionID, familyID)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.repo.CreateRefreshToken(token); err != nil {
+		return "", nil, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+	return raw, token, nil
+}
+
+// RotateRefreshToken implements Service.
+// Refresh token hanya bisa dipakai sekali. Jika token yang sudah dirotasi
+// dipakai lagi, seluruh family dicabut karena kemungkinan token tersebut bocor.
+func (s *service) RotateRefreshToken(raw string) (string, *RefreshToken, error) {
+	if raw == "" {
+		return "", nil, ErrInvalidRefreshToken
+	}
+
+	current, err := s.repo.FindRefreshTokenByHash(HashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil, ErrInvalidRefreshToken
+		}
+		return "", nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if current.RevokedAt != nil {
+		if current.ReplacedByID != nil {
+			s.revokeFamily(current)
+			return "", nil, ErrRefreshTokenReused
+		}
+		return "", nil, ErrInvalidRefreshToken
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return "", nil, ErrInvalidRefreshToken
+	}
+
+	nextRaw, next, err := s.newRefreshToken(current.UserID, current.SessionID, current.FamilyID)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.repo.RotateRefreshToken(current, next); err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			s.revokeFamily(current)
+			return "", nil, ErrRefreshTokenReused
+		}
+		return "", nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	if current.SessionID != 0 {
+		if err := s.repo.TouchSession(current.SessionID, next.ExpiresAt); err != nil {
+			log.Printf("Warning: failed to update session: %v", err)
+		}
+	}
+
+	return nextRaw, next, nil
+}
+
+// RevokeRefreshToken implements Service.
+func (s *service) RevokeRefreshToken(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	token, err := s.repo.FindRefreshTokenByHash(HashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if token.SessionID != 0 {
+		if err := s.repo.RevokeSession(token.SessionID); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+	if err := s.repo.RevokeFamily(token.FamilyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllForUser implements Service.
+func (s *service) RevokeAllForUser(userID uint) error {
+	if err := s.repo.RevokeSessionsByUserID(userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.repo.RevokeAllByUserID(userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// RevokeOtherSessions implements Service.
+func (s *service) RevokeOtherSessions(userID, keepSessionID uint) error {
+	if err := s.repo.RevokeOtherSessions(userID, keepSessionID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// RevokeAccessToken implements Service.
+func (s *service) RevokeAccessToken(jti string, userID uint, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	if err := s.repo.CreateRevokedToken(&RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	// Bersihkan denylist dari token yang memang sudah kadaluarsa
+	if err := s.repo.DeleteExpiredRevokedTokens(); err != nil {
+		log.Printf("Warning: failed to purge revoked tokens: %v", err)
+	}
+	return nil
+}
+
+// revokeFamily mencabut seluruh family (dan session) dari refresh token yang dipakai ulang
+func (s *service) revokeFamily(token *RefreshToken) {
+	if token.SessionID != 0 {
+		if err := s.repo.RevokeSession(token.SessionID); err != nil {
+			log.Printf("Warning: failed to revoke session: %v", err)
+		}
+	}
+	if err := s.repo.RevokeFamily(token.FamilyID); err != nil {
+		log.Printf("Warning: failed to revoke token family: %v", err)
+	}
+}
+
+// CreatePersonalAccessToken implements Service.
+func (s *service) CreatePersonalAccessToken(userID uint, req *CreatePersonalAccessTokenRequest) (*CreatedPersonalAccessTokenResponse, error) {
+	scopes := []string{}
+	seen := map[string]bool{}
+	for _, scope := range req.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !middlewares.IsValidScope(scope) {
+			return nil, fmt.Errorf("invalid scope: %s", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	count, err := s.repo.CountActivePersonalAccessTokens(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count personal access tokens: %w", err)
+	}
+	if count >= maxPersonalAccessTokens {
+		return nil, fmt.Errorf("maximum of %d active personal access tokens reached", maxPersonalAccessTokens)
+	}
+
+	secret, err := GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate personal access token: %w", err)
+	}
+	raw := middlewares.PersonalAccessTokenPrefix + secret
+
+	pat := &PersonalAccessToken{
+		UserID:      userID,
+		Name:        strings.TrimSpace(req.Name),
+		TokenPrefix: raw[:len(middlewares.PersonalAccessTokenPrefix)+6],
+		TokenHash:   HashToken(raw),
+		Scopes:      strings.Join(scopes, " "),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		pat.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.CreatePersonalAccessToken(pat); err != nil {
+		return nil, fmt.Errorf("failed to save personal access token: %w", err)
+	}
+
+	return &CreatedPersonalAccessTokenResponse{
+		Token:                       raw,
+		PersonalAccessTokenResponse: *ToPersonalAccessTokenResponse(pat),
+	}, nil
+}
+
+// GetPersonalAccessTokens implements Service.
+func (s *service) GetPersonalAccessTokens(userID uint) ([]*PersonalAccessTokenResponse, error) {
+	tokens, err := s.repo.FindActivePersonalAccessTokensByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get personal access tokens: %w", err)
+	}
+	responses := []*PersonalAccessTokenResponse{}
+	for _, t := range tokens {
+		responses = append(responses, ToPersonalAccessTokenResponse(t))
+	}
+	return responses, nil
+}
+
+// RevokePersonalAccessToken implements Service.
+func (s *service) RevokePersonalAccessToken(userID, tokenID uint) error {
+	revoked, err := s.repo.RevokePersonalAccessToken(userID, tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke personal access token: %w", err)
+	}
+	if !revoked {
+		return ErrPersonalAccessTokenNotFound
+	}
+	return nil
+}
+
+func NewService(repo Repository, cfg *config.Config) Service {
+	return &service{repo: repo, cfg: cfg}
+}
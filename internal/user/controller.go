@@ -1,8 +1,12 @@
 package user
 
 import (
+	"errors"
 	"fmt"
+	"go-sosmed/internal/token"
 	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/oidc"
+	"go-sosmed/pkg/pagination"
 	"go-sosmed/pkg/response"
 	"net/http"
 	"os"
@@ -42,6 +46,52 @@ func GetUserIDFromContext(c *gin.Context) (uint, bool) {
 	return uid, ok
 }
 
+// GetClientInfo mengambil informasi perangkat dari request untuk dicatat di session
+func GetClientInfo(c *gin.Context) ClientInfo {
+	return ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
+
+const (
+	accessTokenCookie  = "token"
+	refreshTokenCookie = "refresh_token"
+	oidcStateCookie    = "oidc_state"
+	oidcCookiePath     = "/api/auth/oidc"
+)
+
+// setAuthCookies menyimpan access token dan refresh token di cookie httpOnly
+func (ctrl *Controller) setAuthCookies(c *gin.Context, tokens *AuthTokens) {
+	secure := ctrl.config.NodeEnv == "production"
+	c.SetCookie(
+		accessTokenCookie,
+		tokens.AccessToken,
+		int(time.Until(tokens.AccessExpiresAt).Seconds()),
+		"/",
+		ctrl.config.CookieDomain,
+		secure,
+		true,
+	)
+	// refresh token hanya dikirim ke endpoint auth
+	c.SetCookie(
+		refreshTokenCookie,
+		tokens.RefreshToken,
+		int(time.Until(tokens.RefreshExpiresAt).Seconds()),
+		"/api",
+		ctrl.config.CookieDomain,
+		secure,
+		true,
+	)
+}
+
+// clearAuthCookies menghapus cookie access token dan refresh token
+func (ctrl *Controller) clearAuthCookies(c *gin.Context) {
+	secure := ctrl.config.NodeEnv == "production"
+	c.SetCookie(accessTokenCookie, "", -1, "/", ctrl.config.CookieDomain, secure, true)
+	c.SetCookie(refreshTokenCookie, "", -1, "/api", ctrl.config.CookieDomain, secure, true)
+}
+
 // Register godoc
 // @Summary Register user
 // @Description Register
@@ -77,6 +127,7 @@ func (ctrl *Controller) Register(c *gin.Context) {
 // @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
 // @Router /api/login [post]
 func (ctrl *Controller) Login(c *gin.Context) {
 	var req LoginRequest
@@ -84,26 +135,390 @@ func (ctrl *Controller) Login(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "invalid request body")
 		return
 	}
-	token, user, err := ctrl.service.Login(&req)
+	result, err := ctrl.service.Login(&req, GetClientInfo(c))
 	if err != nil {
-		response.Error(c, http.StatusUnauthorized, err.Error())
+		writeLoginError(c, err)
+		return
+	}
+	ctrl.writeLoginResult(c, result)
+}
+
+// writeLoginError mengirim 429 + Retry-After saat login dikunci, selain itu 401
+func writeLoginError(c *gin.Context, err error) {
+	var lockedErr *LoginLockedError
+	if errors.As(err, &lockedErr) {
+		c.Header("Retry-After", strconv.Itoa(int(lockedErr.RetryAfter.Seconds())+1))
+		response.Error(c, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	var suspendedErr *AccountSuspendedError
+	if errors.As(err, &suspendedErr) {
+		response.Error(c, http.StatusForbidden, err.Error())
+		return
+	}
+	response.Error(c, http.StatusUnauthorized, err.Error())
+}
+
+// writeLoginResult mengirim response login: challenge 2FA atau cookie session
+func (ctrl *Controller) writeLoginResult(c *gin.Context, result *LoginResult) {
+	if result.ChallengeToken != "" {
+		response.Success(c, http.StatusOK, "two-factor authentication required", gin.H{
+			"two_factor_required": true,
+			"challenge_token":     result.ChallengeToken,
+			"expires_at":          result.ChallengeExpiresAt,
+		})
+		return
+	}
+	ctrl.setAuthCookies(c, result.Tokens)
+	response.Success(c, http.StatusOK, "login successful", gin.H{
+		"user": result.User,
+	})
+}
+
+// OIDCLogin godoc
+// @Summary Start social login
+// @Description Redirect to the OpenID Connect provider (authorization code + PKCE)
+// @Tags User
+// @Param provider path string true "Provider name, e.g. google"
+// @Success 302
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Router /api/auth/oidc/{provider}/login [get]
+func (ctrl *Controller) OIDCLogin(c *gin.Context) {
+	authURL, stateToken, err := ctrl.service.StartOIDCLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, oidc.ErrProviderNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadGateway, err.Error())
 		return
 	}
 	c.SetCookie(
-		"token",
-		token,
-		int((7 * 24 * time.Hour).Seconds()),
-		"/",
-		"",
+		oidcStateCookie,
+		stateToken,
+		int(oidcStateTTL.Seconds()),
+		oidcCookiePath,
+		ctrl.config.CookieDomain,
 		ctrl.config.NodeEnv == "production",
 		true,
 	)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback godoc
+// @Summary Complete social login
+// @Description Callback from the OpenID Connect provider. Links the provider account to an existing user with the same verified email, or creates a new user.
+// @Tags User
+// @Produce json
+// @Param provider path string true "Provider name, e.g. google"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/auth/oidc/{provider}/callback [get]
+func (ctrl *Controller) OIDCCallback(c *gin.Context) {
+	var req OIDCCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request query")
+		return
+	}
+	stateToken, _ := c.Cookie(oidcStateCookie)
+	// state hanya boleh dipakai sekali
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, ctrl.config.CookieDomain, ctrl.config.NodeEnv == "production", true)
+
+	result, err := ctrl.service.CompleteOIDCLogin(c.Request.Context(), c.Param("provider"), &req, stateToken, GetClientInfo(c))
+	if err != nil {
+		if errors.Is(err, oidc.ErrProviderNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	ctrl.writeLoginResult(c, result)
+}
+
+// VerifyTwoFactorLogin godoc
+// @Summary Complete login with two-factor code
+// @Description Exchange the login challenge token and a TOTP or recovery code for the session cookies
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param data body TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Router /api/login/2fa [post]
+func (ctrl *Controller) VerifyTwoFactorLogin(c *gin.Context) {
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	tokens, user, err := ctrl.service.VerifyTwoFactorLogin(&req, GetClientInfo(c))
+	if err != nil {
+		writeLoginError(c, err)
+		return
+	}
+	ctrl.setAuthCookies(c, tokens)
 	response.Success(c, http.StatusOK, "login successful", gin.H{
 		"user": user,
-		// "token": token,
 	})
 }
 
+// EnrollTwoFactor godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and otpauth URI for the current user
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /api/users/me/2fa/enroll [post]
+func (ctrl *Controller) EnrollTwoFactor(c *gin.Context) {
+	authUserID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	resp, err := ctrl.service.EnrollTwoFactor(authUserID)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "scan the otpauth URI with your authenticator app", resp)
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Confirm two-factor enrollment
+// @Description Enable 2FA with a valid TOTP code and receive one-time recovery codes
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param data body TwoFactorCodeRequest true "TOTP code"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /api/users/me/2fa/confirm [post]
+func (ctrl *Controller) ConfirmTwoFactor(c *gin.Context) {
+	authUserID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	resp, err := ctrl.service.ConfirmTwoFactor(authUserID, req.Code)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "two-factor authentication enabled", resp)
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Disable 2FA using the current password and a valid TOTP code
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param data body TwoFactorDisableRequest true "Password and TOTP code"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /api/users/me/2fa/disable [post]
+func (ctrl *Controller) DisableTwoFactor(c *gin.Context) {
+	authUserID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req TwoFactorDisableRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := ctrl.service.DisableTwoFactor(authUserID, &req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "two-factor authentication disabled", nil)
+}
+
+// RefreshToken godoc
+// @Summary Refresh access token
+// @Description Rotate the refresh token (cookie or body) and issue a new short-lived access token
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param data body token.RefreshTokenRequest false "Refresh token (optional if sent as cookie)"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /api/token/refresh [post]
+func (ctrl *Controller) RefreshToken(c *gin.Context) {
+	refreshToken, _ := c.Cookie(refreshTokenCookie)
+	if refreshToken == "" {
+		var req token.RefreshTokenRequest
+		_ = c.ShouldBind(&req)
+		refreshToken = req.RefreshToken
+	}
+
+	tokens, err := ctrl.service.RefreshToken(refreshToken)
+	if err != nil {
+		if errors.Is(err, token.ErrRefreshTokenReused) || errors.Is(err, token.ErrInvalidRefreshToken) {
+			ctrl.clearAuthCookies(c)
+			response.Error(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		var suspendedErr *AccountSuspendedError
+		if errors.As(err, &suspendedErr) {
+			ctrl.clearAuthCookies(c)
+			response.Error(c, http.StatusForbidden, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctrl.setAuthCookies(c, tokens)
+	response.Success(c, http.StatusOK, "token refreshed successfully", gin.H{
+		"expires_at": tokens.AccessExpiresAt,
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Confirm an email address using the signed link sent after registration
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param token query string false "Verification token (link)"
+// @Param data body VerifyEmailRequest false "Verification token"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/email/verify [get]
+// @Router /api/email/verify [post]
+func (ctrl *Controller) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "verification token is required")
+		return
+	}
+	if err := ctrl.service.VerifyEmail(req.Token); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "email verified successfully", nil)
+}
+
+// ResendVerificationEmail godoc
+// @Summary Resend verification email
+// @Description Send a new verification link to the current user's email address
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /api/email/verify/resend [post]
+func (ctrl *Controller) ResendVerificationEmail(c *gin.Context) {
+	authUserID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if err := ctrl.service.ResendVerificationEmail(authUserID); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "verification email sent", nil)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use, expiring password reset link (always succeeds to avoid email enumeration)
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param data body ForgotPasswordRequest true "Account email"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/password/forgot [post]
+func (ctrl *Controller) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := ctrl.service.ForgotPassword(&req); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "if the email is registered, a reset link has been sent", nil)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using a reset token; all existing sessions are signed out
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param data body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/password/reset [post]
+func (ctrl *Controller) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := ctrl.service.ResetPassword(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "password reset successfully", nil)
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the current user's password; other sessions are signed out
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param data body ChangePasswordRequest true "Current and new password"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /api/users/me/password [put]
+func (ctrl *Controller) ChangePassword(c *gin.Context) {
+	authUserID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req ChangePasswordRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := ctrl.service.ChangePassword(authUserID, c.GetUint("sessionID"), &req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "password changed successfully", nil)
+}
+
 // GetUserByID godoc
 // @Summary Get user by ID
 // @Description Retrieve user information by user ID
@@ -130,15 +545,251 @@ func (ctrl *Controller) GetUserByID(c *gin.Context) {
 	response.Success(c, http.StatusOK, "user fetched successfully", user)
 }
 
+// UnlockAccount godoc
+// @Summary Unlock user account
+// @Description Clear the failed-login lockout of a user (admin only)
+// @Tags Admin
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/admin/users/{user_id}/unlock [post]
+func (ctrl *Controller) UnlockAccount(c *gin.Context) {
+	userID, err := ParseUserID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+	if err := ctrl.service.UnlockAccount(userID); err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "account unlocked successfully", nil)
+}
+
+// AssignRole godoc
+// @Summary Assign user role
+// @Description Change the role of a user (admin, moderator or user)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param data body AssignRoleRequest true "New role"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/admin/users/{user_id}/role [put]
+func (ctrl *Controller) AssignRole(c *gin.Context) {
+	actorID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	userID, err := ParseUserID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+	var req AssignRoleRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	user, err := ctrl.service.AssignRole(actorID, userID, req.Role)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "role updated successfully", user)
+}
+
+// DeleteAccount godoc
+// @Summary Delete own account
+// @Description Schedule deletion of the current account after a grace period. Signing in again before then cancels the deletion.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param data body DeleteAccountRequest true "Current password"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /api/users/me [delete]
+func (ctrl *Controller) DeleteAccount(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	var req DeleteAccountRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	scheduledAt, err := ctrl.service.ScheduleAccountDeletion(userID, &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctrl.clearAuthCookies(c)
+	response.Success(c, http.StatusOK, "account deletion scheduled, sign in again to cancel", gin.H{
+		"deletion_scheduled_at": scheduledAt,
+	})
+}
+
+// SuspendUser godoc
+// @Summary Suspend user
+// @Description Suspend a user for a number of hours and sign them out of all devices
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param data body SuspendUserRequest true "Reason and duration"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/admin/users/{user_id}/suspend [post]
+func (ctrl *Controller) SuspendUser(c *gin.Context) {
+	actorID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	userID, err := ParseUserID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+	var req SuspendUserRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	suspension, err := ctrl.service.SuspendUser(actorID, c.GetString("userRole"), userID, &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "user suspended successfully", suspension)
+}
+
+// BanUser godoc
+// @Summary Ban user
+// @Description Permanently ban a user and sign them out of all devices. Requires the user.ban permission (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param data body BanUserRequest true "Reason"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/admin/users/{user_id}/ban [post]
+func (ctrl *Controller) BanUser(c *gin.Context) {
+	actorID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	userID, err := ParseUserID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+	var req BanUserRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	suspension, err := ctrl.service.BanUser(actorID, c.GetString("userRole"), userID, &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "user banned successfully", suspension)
+}
+
+// LiftSuspension godoc
+// @Summary Lift suspension or ban
+// @Description Restore a suspended or banned user. Moderators cannot lift bans or act on privileged accounts
+// @Tags Admin
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/admin/users/{user_id}/suspension [delete]
+func (ctrl *Controller) LiftSuspension(c *gin.Context) {
+	actorID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	userID, err := ParseUserID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+	if err := ctrl.service.LiftSuspension(actorID, c.GetString("userRole"), userID); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "suspension lifted successfully", nil)
+}
+
+// GetSuspensions godoc
+// @Summary List suspensions
+// @Description List suspension and ban history, newest first
+// @Tags Admin
+// @Produce json
+// @Param active query bool false "Only active suspensions"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Security BearerAuth
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/admin/suspensions [get]
+func (ctrl *Controller) GetSuspensions(c *gin.Context) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	activeOnly := c.Query("active") == "true"
+
+	suspensions, next, err := ctrl.service.GetSuspensions(activeOnly, page)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response.Paginated(c, http.StatusOK, "suspensions fetched successfully", suspensions, next)
+}
+
 // UpdateProfile godoc
 // @Summary Update user profile
 // @Description Update authenticated user's profile information
 // @Tags User
 // @Accept multipart/form-data
 // @Produce json
-// @Param username formData string false "Username"
+// @Param username formData string false "Username. The old username keeps pointing to this account and is reserved for a while; usernames can only be changed once per cooldown period. Changing the username removes the verified badge until a new verification request is approved."
 // @Param bio formData string false "User bio"
+// @Param display_name formData string false "Display name (max 50 characters)"
+// @Param location formData string false "Location (max 100 characters)"
+// @Param links formData string false "JSON array of up to 5 links, e.g. [{\"label\":\"Blog\",\"url\":\"https://example.com\"}]. An empty array removes all links."
+// @Param birthday formData string false "Birthday in YYYY-MM-DD format, empty to remove"
+// @Param birthday_visibility formData string false "Who can see the birthday" Enums(private, month_day, public)
+// @Param is_private formData bool false "Private account"
 // @Param avatar formData file false "Avatar image"
+// @Param banner formData file false "Banner image"
 // @Security BearerAuth
 // @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
@@ -165,6 +816,7 @@ func (ctrl *Controller) UpdateProfile(c *gin.Context) {
 	}
 
 	oldAvatar := oldUser.Avatar
+	oldBanner := oldUser.Banner
 
 	if uploadedFile, exists := c.Get("uploadedFile"); exists {
 		fileStr := uploadedFile.(string)
@@ -172,10 +824,24 @@ func (ctrl *Controller) UpdateProfile(c *gin.Context) {
 			req.Avatar = &fileStr
 		}
 	}
+	if uploadedBanner, exists := c.Get("uploadedBanner"); exists {
+		fileStr := uploadedBanner.(string)
+		if fileStr != "" {
+			req.Banner = &fileStr
+		}
+	}
 
 	user, err := ctrl.service.UpdateProfile(authUserID, &req)
 	if err != nil {
-		if err.Error() == "username already in use" || err.Error() == "email already in use" {
+		var tooSoonErr *UsernameChangeTooSoonError
+		if errors.As(err, &tooSoonErr) {
+			c.Header("Retry-After", strconv.Itoa(int(tooSoonErr.RetryAfter.Seconds())+1))
+			response.Error(c, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		if err.Error() == "username already in use" || err.Error() == "email already in use" ||
+			errors.Is(err, ErrUsernameReserved) ||
+			errors.Is(err, ErrInvalidBirthday) || errors.Is(err, ErrInvalidProfileLink) || errors.Is(err, ErrTooManyProfileLinks) {
 			response.Error(c, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -183,10 +849,13 @@ func (ctrl *Controller) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	// Delete old avatar file if a new one was uploaded
+	// Delete old avatar/banner file if a new one was uploaded
 	if req.Avatar != nil && oldAvatar != "" && oldAvatar != *req.Avatar {
 		_ = os.Remove("." + oldAvatar)
 	}
+	if req.Banner != nil && oldBanner != "" && oldBanner != *req.Banner {
+		_ = os.Remove("." + oldBanner)
+	}
 
 	response.Success(c, http.StatusOK, "profile updated successfully", user)
 }
@@ -257,28 +926,19 @@ func (ctrl *Controller) GetExploreUsers(c *gin.Context) {
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		response.Error(c, http.StatusBadRequest, "invalid limit parameter")
-		return
-	}
-
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		response.Error(c, http.StatusBadRequest, "invalid offset parameter")
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	users, err := ctrl.service.GetExploreUsers(authUserID, limit, offset)
+	users, next, err := ctrl.service.GetExploreUsers(authUserID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response.Success(c, http.StatusOK, "explore users fetched successfully", users)
+	response.Paginated(c, http.StatusOK, "explore users fetched successfully", users, next)
 }
 
 // GetUserDetailByID godoc
@@ -312,6 +972,9 @@ func (ctrl *Controller) GetUserDetailByUsername(c *gin.Context) {
 		response.Error(c, http.StatusNotFound, err.Error())
 		return
 	}
+	if user.RedirectedFrom != "" {
+		c.Header("Content-Location", "/api/users/username/"+user.Username)
+	}
 
 	response.Success(c, http.StatusOK, "user detail fetched successfully", user)
 }
@@ -324,13 +987,19 @@ func (ctrl *Controller) SearchUser(c *gin.Context) {
 		return
 	}
 
-	users, err := ctrl.service.SearchUser(keyword, authUserID)
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, next, err := ctrl.service.SearchUser(keyword, authUserID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response.Success(c, http.StatusOK, "users fetched successfully", users)
+	response.Paginated(c, http.StatusOK, "users fetched successfully", users, next)
 }
 
 func (ctrl *Controller) GetUserFollowers(c *gin.Context) {
@@ -340,82 +1009,202 @@ func (ctrl *Controller) GetUserFollowers(c *gin.Context) {
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "50")
-	offsetStr := c.DefaultQuery("offset", "0")
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, next, err := ctrl.service.GetUserFollowers(authUserID, page)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Paginated(c, http.StatusOK, "user followers fetched successfully", users, next)
+}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		response.Error(c, http.StatusBadRequest, "invalid limit parameter")
+// GetFollowersByUsername godoc
+// @Summary Get followers of a user
+// @Description Get the followers of a user by username. The list of a private account is only visible to its approved followers.
+// @Tags User
+// @Produce json
+// @Param username path string true "Username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Security BearerAuth
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/users/username/{username}/followers [get]
+func (ctrl *Controller) GetFollowersByUsername(c *gin.Context) {
+	username, err := ParseUsername(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid username")
+		return
+	}
+	authUserID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		response.Error(c, http.StatusBadRequest, "invalid offset parameter")
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	users, err := ctrl.service.GetUserFollowers(authUserID, limit, offset)
+	users, next, err := ctrl.service.GetFollowersByUsername(username, authUserID, page)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		switch {
+		case errors.Is(err, ErrPrivateAccount):
+			response.Error(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrUserNotFound):
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
-	response.Success(c, http.StatusOK, "user followers fetched successfully", users)
+	response.Paginated(c, http.StatusOK, "user followers fetched successfully", users, next)
 }
 
-func (ctrl *Controller) GetUserFollowings(c *gin.Context) {
+// GetFollowingsByUsername godoc
+// @Summary Get followings of a user
+// @Description Get the followings of a user by username. The list of a private account is only visible to its approved followers.
+// @Tags User
+// @Produce json
+// @Param username path string true "Username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Security BearerAuth
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/users/username/{username}/followings [get]
+func (ctrl *Controller) GetFollowingsByUsername(c *gin.Context) {
+	username, err := ParseUsername(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid username")
+		return
+	}
 	authUserID, ok := GetUserIDFromContext(c)
 	if !ok {
 		response.Error(c, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "50")
-	offsetStr := c.DefaultQuery("offset", "0")
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, next, err := ctrl.service.GetFollowingsByUsername(username, authUserID, page)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPrivateAccount):
+			response.Error(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrUserNotFound):
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	response.Paginated(c, http.StatusOK, "user followings fetched successfully", users, next)
+}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		response.Error(c, http.StatusBadRequest, "invalid limit parameter")
+func (ctrl *Controller) GetUserFollowings(c *gin.Context) {
+	authUserID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		response.Error(c, http.StatusBadRequest, "invalid offset parameter")
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	users, err := ctrl.service.GetUserFollowings(authUserID, limit, offset)
+	users, next, err := ctrl.service.GetUserFollowings(authUserID, page)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response.Success(c, http.StatusOK, "user followings fetched successfully", users)
+	response.Paginated(c, http.StatusOK, "user followings fetched successfully", users, next)
 }
 
+// Logout godoc
+// @Summary Logout
+// @Description Revoke the current access token and its refresh token family
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/logout [post]
 func (ctrl *Controller) Logout(c *gin.Context) {
-	err := ctrl.service.Logout()
+	authUserID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	refreshToken, _ := c.Cookie(refreshTokenCookie)
+	err := ctrl.service.Logout(
+		authUserID,
+		c.GetUint("sessionID"),
+		c.GetString("tokenID"),
+		c.GetTime("tokenExpiresAt"),
+		refreshToken,
+	)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "failed to logout")
 		return
 	}
 
-	// Clear the token cookie
-	c.SetCookie(
-		"token",
-		"",
-		-1,
-		"/",
-		ctrl.config.CookieDomain, // domain dari env
-		ctrl.config.NodeEnv == "production",
-		true,
-	)
+	// Clear the token cookies
+	ctrl.clearAuthCookies(c)
 
 	response.Success(c, http.StatusOK, "logout successful", nil)
 }
 
+// LogoutAll godoc
+// @Summary Logout from all devices
+// @Description Revoke every refresh token and every access token issued to the current user
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/logout/all [post]
+func (ctrl *Controller) LogoutAll(c *gin.Context) {
+	authUserID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := ctrl.service.LogoutAll(authUserID); err != nil {
+		response.Error(c, http.StatusInternalServerError, "failed to logout")
+		return
+	}
+
+	ctrl.clearAuthCookies(c)
+
+	response.Success(c, http.StatusOK, "logged out from all devices", nil)
+}
+
 func NewController(s Service, cfg *config.Config) *Controller {
 	return &Controller{
 		service: s,
@@ -0,0 +1,36 @@
+package user
+
+import "gorm.io/gorm"
+
+// AdjustFollowCounts mengubah following_count followerID dan followers_count
+// followingID sebesar delta. Hanya follow yang sudah disetujui yang dihitung,
+// dan dipanggil di dalam transaksi yang sama dengan perubahan tabel follows.
+func AdjustFollowCounts(db *gorm.DB, followerID, followingID uint, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	err := db.Model(&User{}).
+		Where("id = ?", followerID).
+		UpdateColumn("following_count", gorm.Expr("GREATEST(following_count + ?, 0)", delta)).Error
+	if err != nil {
+		return err
+	}
+	return db.Model(&User{}).
+		Where("id = ?", followingID).
+		UpdateColumn("followers_count", gorm.Expr("GREATEST(followers_count + ?, 0)", delta)).Error
+}
+
+// RecountFollowCounts menghitung ulang followers_count dan following_count user
+// dengan id di ids dari tabel follows. Mengembalikan jumlah user yang nilainya berubah.
+func RecountFollowCounts(db *gorm.DB, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := db.Exec(`
+		UPDATE users SET
+			followers_count = (SELECT COUNT(*) FROM follows WHERE follows.following_id = users.id AND follows.status = ?),
+			following_count = (SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id AND follows.status = ?)
+		WHERE users.id IN ?
+	`, FollowStatusAccepted, FollowStatusAccepted, ids)
+	return result.RowsAffected, result.Error
+}
@@ -1,5 +1,16 @@
 package user
 
+import (
+	"encoding/json"
+	"go-sosmed/pkg/pagination"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
 func ToUserResponse(u *User) *UserResponse {
 	return &UserResponse{
 		ID:             u.ID,
@@ -7,9 +18,199 @@ func ToUserResponse(u *User) *UserResponse {
 		Email:          u.Email,
 		Bio:            u.Bio,
 		Avatar:         u.Avatar,
+		DisplayName:    u.DisplayName,
+		Location:       u.Location,
+		Links:          u.Links,
+		Banner:         u.Banner,
+		Birthday:       publicBirthday(u),
 		FollowersCount: u.FollowersCount,
 		FollowingCount: u.FollowingCount,
 		IsFollowed:     u.IsFollowed,
+		IsRequested:    u.IsRequested,
+		IsPrivate:      u.IsPrivate,
+		IsVerified:     u.VerifiedAt != nil,
 		Role:           u.Role,
 	}
 }
+
+// withAccountStatus menambahkan status akun (verifikasi email, 2FA, suspensi,
+// jadwal penghapusan) yang tidak boleh terlihat oleh user lain
+func withAccountStatus(resp *UserResponse, u *User) *UserResponse {
+	emailVerified := u.EmailVerifiedAt != nil
+	twoFactor := u.TwoFactorEnabled
+	resp.EmailVerified = &emailVerified
+	resp.TwoFactor = &twoFactor
+	resp.SuspendedUntil = activeSuspension(u)
+	resp.Banned = u.BannedAt != nil
+	resp.DeletionScheduledAt = u.DeletionScheduledAt
+	return resp
+}
+
+// ToAdminUserResponse dipakai pada endpoint admin: profil publik ditambah status akun
+func ToAdminUserResponse(u *User) *UserResponse {
+	return withAccountStatus(ToUserResponse(u), u)
+}
+
+// ToOwnUserResponse dipakai untuk profil milik user sendiri: tanggal lahir selalu
+// ditampilkan lengkap beserta pengaturan visibilitasnya, ditambah status akun.
+func ToOwnUserResponse(u *User) *UserResponse {
+	resp := withAccountStatus(ToUserResponse(u), u)
+	if u.Birthday != nil {
+		resp.Birthday = u.Birthday.Format(birthdayLayout)
+	}
+	resp.BirthdayVisibility = u.BirthdayVisibility
+	if resp.BirthdayVisibility == "" {
+		resp.BirthdayVisibility = BirthdayPrivate
+	}
+	return resp
+}
+
+const birthdayLayout = "2006-01-02"
+
+// publicBirthday mengembalikan tanggal lahir sesuai pengaturan visibilitas
+func publicBirthday(u *User) string {
+	if u.Birthday == nil {
+		return ""
+	}
+	switch u.BirthdayVisibility {
+	case BirthdayPublic:
+		return u.Birthday.Format(birthdayLayout)
+	case BirthdayMonthDay:
+		return u.Birthday.Format("01-02")
+	default:
+		return ""
+	}
+}
+
+// parseBirthday memvalidasi tanggal lahir (YYYY-MM-DD); string kosong berarti dihapus
+func parseBirthday(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	birthday, err := time.Parse(birthdayLayout, value)
+	if err != nil {
+		return nil, ErrInvalidBirthday
+	}
+	if birthday.After(time.Now()) || birthday.Year() < 1900 {
+		return nil, ErrInvalidBirthday
+	}
+	return &birthday, nil
+}
+
+// parseProfileLinks memvalidasi link profil yang dikirim sebagai JSON array.
+// Hanya URL http/https yang diterima; label kosong diganti dengan host URL.
+func parseProfileLinks(value string) ([]ProfileLink, error) {
+	links := []ProfileLink{}
+	if strings.TrimSpace(value) == "" {
+		return links, nil
+	}
+	if err := json.Unmarshal([]byte(value), &links); err != nil {
+		return nil, ErrInvalidProfileLink
+	}
+	if len(links) > MaxProfileLinks {
+		return nil, ErrTooManyProfileLinks
+	}
+
+	for i := range links {
+		link := &links[i]
+		link.URL = strings.TrimSpace(link.URL)
+		link.Label = strings.TrimSpace(link.Label)
+		if link.URL == "" || len(link.URL) > maxProfileLinkLength {
+			return nil, ErrInvalidProfileLink
+		}
+		parsed, err := url.Parse(link.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.User != nil {
+			return nil, ErrInvalidProfileLink
+		}
+		if link.Label == "" {
+			link.Label = parsed.Hostname()
+		}
+		if utf8.RuneCountInString(link.Label) > maxProfileLinkLabel {
+			return nil, ErrInvalidProfileLink
+		}
+	}
+	return links, nil
+}
+
+// activeSuspension mengembalikan akhir masa suspend jika masih berlaku
+func activeSuspension(u *User) *time.Time {
+	if u.SuspendedUntil != nil && u.SuspendedUntil.After(time.Now()) {
+		return u.SuspendedUntil
+	}
+	return nil
+}
+
+// checkAccountStatus menolak akun yang sedang di-ban atau di-suspend
+func checkAccountStatus(u *User) error {
+	if u.BannedAt != nil {
+		return &AccountSuspendedError{Reason: u.SuspensionReason}
+	}
+	if until := activeSuspension(u); until != nil {
+		return &AccountSuspendedError{Until: until, Reason: u.SuspensionReason}
+	}
+	return nil
+}
+
+func ToSuspensionResponse(s *UserSuspension) *SuspensionResponse {
+	return &SuspensionResponse{
+		ID:         s.ID,
+		UserID:     s.UserID,
+		Username:   s.User.Username,
+		ActorID:    s.ActorID,
+		Type:       s.Type,
+		Reason:     s.Reason,
+		StartsAt:   s.StartsAt,
+		EndsAt:     s.EndsAt,
+		LiftedAt:   s.LiftedAt,
+		LiftedByID: s.LiftedByID,
+	}
+}
+
+// NotBlocked menyembunyikan baris milik user yang memblokir atau diblokir viewerID.
+// column adalah kolom user ID pada query (misal "users.id" atau "posts.author_id").
+// Tabel blocks dikelola package block; helper ada di sini agar bisa dipakai semua
+// repository tanpa import cycle.
+func NotBlocked(column string, viewerID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db
+		}
+		return db.Where(
+			column+" NOT IN (SELECT blocked_id FROM blocks WHERE blocker_id = ?) AND "+
+				column+" NOT IN (SELECT blocker_id FROM blocks WHERE blocked_id = ?)",
+			viewerID, viewerID,
+		)
+	}
+}
+
+// IsAcceptedFollower mengecek apakah followerID mengikuti followingID dan sudah disetujui
+func IsAcceptedFollower(db *gorm.DB, followerID, followingID uint) (bool, error) {
+	var count int64
+	err := db.Table("follows").
+		Where("follower_id = ? AND following_id = ? AND status = ?", followerID, followingID, FollowStatusAccepted).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// IsBlockedBetween mengecek apakah salah satu dari dua user memblokir yang lain
+func IsBlockedBetween(db *gorm.DB, userA, userB uint) (bool, error) {
+	var count int64
+	err := db.Table("blocks").
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userA, userB, userB, userA).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func toUserResponses(users []*User) []*UserResponse {
+	responses := make([]*UserResponse, 0, len(users))
+	for _, u := range users {
+		responses = append(responses, ToUserResponse(u))
+	}
+	return responses
+}
+
+// followCursor adalah posisi user di daftar follower/following
+func followCursor(u *User) pagination.Cursor {
+	return pagination.Cursor{ID: u.FollowID}
+}
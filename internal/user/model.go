@@ -1,10 +1,24 @@
 package user
 
+import (
+	"fmt"
+	"go-sosmed/pkg/rbac"
+	"time"
+)
+
 type RoleType = string
 
 const (
-	RoleAdmin RoleType = "admin"
-	RoleUser  RoleType = "user"
+	RoleAdmin     RoleType = rbac.RoleAdmin
+	RoleModerator RoleType = rbac.RoleModerator
+	RoleUser      RoleType = rbac.RoleUser
+)
+
+// Status baris di tabel follows. Didefinisikan di sini karena query user ikut
+// menghitung follower; package follow memakai konstanta yang sama.
+const (
+	FollowStatusPending  = "pending"
+	FollowStatusAccepted = "accepted"
 )
 
 type User struct {
@@ -15,10 +29,61 @@ type User struct {
 	Bio      string   `gorm:"type:text"`
 	Avatar   string   `gorm:"type:text"`
 	Role     RoleType `gorm:"default:'user'"`
+	// profil tambahan
+	DisplayName        string        `gorm:"size:50"`
+	Location           string        `gorm:"size:100"`
+	Links              []ProfileLink `gorm:"type:text;serializer:json"`
+	Banner             string        `gorm:"type:text"`
+	Birthday           *time.Time    `gorm:"type:date"`
+	BirthdayVisibility string        `gorm:"size:16;default:'private'"`
+	// waktu terakhir username diganti, untuk cooldown penggantian username
+	UsernameChangedAt *time.Time
+	// badge akun terverifikasi, diberikan admin lewat verification request
+	VerifiedAt *time.Time
+	// akun private: follow harus disetujui dan post hanya terlihat oleh follower
+	IsPrivate bool `gorm:"default:false"`
+	// access token yang diterbitkan sebelum waktu ini dianggap tidak valid
+	TokensRevokedAt *time.Time
+	EmailVerifiedAt *time.Time
+	// two-factor authentication (TOTP)
+	TOTPSecret        string `gorm:"size:64"`
+	TOTPLastStep      int64  `gorm:"default:0"` // time-step terakhir yang dipakai, mencegah replay kode
+	TwoFactorEnabled  bool   `gorm:"default:false"`
+	TwoFactorRequired bool   `gorm:"default:false"` // dipaksa oleh admin/seeder
+	// moderasi akun: suspend (sementara) atau ban (permanen)
+	SuspendedUntil   *time.Time
+	BannedAt         *time.Time
+	SuspensionReason string `gorm:"type:text"`
+	// penghapusan akun: dijadwalkan oleh user, dieksekusi oleh job account purge
+	DeletionScheduledAt *time.Time `gorm:"index"`
+	PurgedAt            *time.Time
+	// counter follow yang disetujui, hanya diubah lewat AdjustFollowCounts dan RecountFollowCounts
+	FollowersCount int64 `gorm:"not null;default:0;<-:false"`
+	FollowingCount int64 `gorm:"not null;default:0;<-:false"`
 	//computed fields
-	FollowersCount int64 `gorm:"-:migration;<-:false"` // ignored by GORM migrations and write operations
-	FollowingCount int64 `gorm:"-:migration;<-:false"` // ignored by GORM migrations and write operations
-	IsFollowed     bool  `gorm:"-:migration;<-:false"` // ignored by GORM migrations and write operations
+	IsFollowed  bool `gorm:"-:migration;<-:false"` // ignored by GORM migrations and write operations
+	IsRequested bool `gorm:"-:migration;<-:false"` // ignored by GORM migrations and write operations
+	FollowID    uint `gorm:"-:migration;<-:false"` // id baris follows pada daftar follower/following, untuk cursor
+}
+
+// Visibilitas tanggal lahir di profil publik. Pemilik akun selalu melihat tanggal lengkap.
+const (
+	BirthdayPrivate  = "private"
+	BirthdayMonthDay = "month_day" // hanya bulan dan tanggal, tanpa tahun
+	BirthdayPublic   = "public"
+)
+
+// batasan link di profil
+const (
+	MaxProfileLinks      = 5
+	maxProfileLinkLength = 255
+	maxProfileLinkLabel  = 30
+)
+
+// ProfileLink adalah satu link yang ditampilkan di profil (website, portfolio, dll)
+type ProfileLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
 }
 
 type RegisterRequest struct {
@@ -32,6 +97,208 @@ type LoginRequest struct {
 	Password string `json:"password" form:"password" binding:"required"`
 }
 
+// AuthTokens berisi pasangan access token (JWT) dan refresh token
+type AuthTokens struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// ClientInfo berisi informasi perangkat yang dicatat pada session login
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// PasswordResetToken adalah token sekali pakai untuk reset password (disimpan dalam bentuk hash)
+type PasswordResetToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// RecoveryCode adalah kode cadangan sekali pakai jika perangkat authenticator hilang
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// LoginThrottle mencatat percobaan login gagal per akun (email) atau per IP.
+// Key berbentuk "email:<email>" atau "ip:<alamat>".
+type LoginThrottle struct {
+	Key          string    `gorm:"column:throttle_key;primaryKey;size:191"`
+	FailedCount  int       `gorm:"not null;default:0"`
+	LastFailedAt time.Time `gorm:"not null"`
+	LockedUntil  *time.Time
+}
+
+// LoginLockedError dikembalikan saat akun atau IP sedang dikunci karena terlalu banyak gagal login
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return "too many failed login attempts, try again later"
+}
+
+const (
+	SuspensionTypeSuspend = "suspend"
+	SuspensionTypeBan     = "ban"
+)
+
+// UserSuspension adalah riwayat tindakan suspend/ban terhadap sebuah akun
+type UserSuspension struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	User       User   `gorm:"foreignKey:UserID"`
+	ActorID    uint   `gorm:"not null"`
+	Type       string `gorm:"size:16;not null"`
+	Reason     string `gorm:"type:text;not null"`
+	StartsAt   time.Time
+	EndsAt     *time.Time // nil untuk ban permanen
+	LiftedAt   *time.Time
+	LiftedByID *uint
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// AccountSuspendedError dikembalikan saat akun yang ditangguhkan atau diblokir mencoba login
+type AccountSuspendedError struct {
+	Until  *time.Time // nil berarti ban permanen
+	Reason string
+}
+
+func (e *AccountSuspendedError) Error() string {
+	if e.Until == nil {
+		return "account has been banned"
+	}
+	return "account is suspended until " + e.Until.UTC().Format(time.RFC3339)
+}
+
+type SuspendUserRequest struct {
+	Reason        string `json:"reason" form:"reason" binding:"required,max=500"`
+	DurationHours int    `json:"duration_hours" form:"duration_hours" binding:"required,min=1,max=8760"`
+}
+
+type BanUserRequest struct {
+	Reason string `json:"reason" form:"reason" binding:"required,max=500"`
+}
+
+type SuspensionResponse struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	Username   string     `json:"username"`
+	ActorID    uint       `json:"actor_id"`
+	Type       string     `json:"type"`
+	Reason     string     `json:"reason"`
+	StartsAt   time.Time  `json:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at"`
+	LiftedAt   *time.Time `json:"lifted_at"`
+	LiftedByID *uint      `json:"lifted_by_id"`
+}
+
+// UsernameHistory mencatat username lama. Username lama tetap diarahkan ke akun
+// pemiliknya dan tidak bisa dipakai user lain sampai ReservedUntil.
+type UsernameHistory struct {
+	ID            uint      `gorm:"primaryKey"`
+	UserID        uint      `gorm:"not null;index"`
+	Username      string    `gorm:"size:191;not null;index"`
+	ReservedUntil time.Time `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"` // waktu username diganti
+}
+
+// UsernameChangeTooSoonError dikembalikan jika username diganti lagi sebelum cooldown habis
+type UsernameChangeTooSoonError struct {
+	RetryAfter time.Duration
+}
+
+func (e *UsernameChangeTooSoonError) Error() string {
+	return fmt.Sprintf("username was changed recently, try again in %s", e.RetryAfter.Round(time.Hour))
+}
+
+// UserIdentity menghubungkan user dengan akun di provider OpenID Connect (social login)
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	Provider  string    `gorm:"size:32;not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject   string    `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject"`
+	Email     string    `gorm:"size:255"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// OIDCCallbackRequest adalah query string yang dikirim provider ke callback URL
+type OIDCCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// LoginResult adalah hasil login. Jika 2FA aktif, hanya ChallengeToken yang terisi
+// dan token asli baru diterbitkan setelah kode TOTP diverifikasi.
+type LoginResult struct {
+	Tokens             *AuthTokens
+	User               *UserResponse
+	ChallengeToken     string
+	ChallengeExpiresAt time.Time
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" form:"code" binding:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" form:"password" binding:"required"`
+	Code     string `json:"code" form:"code" binding:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" form:"challenge_token" binding:"required"`
+	Code           string `json:"code" form:"code"`
+	RecoveryCode   string `json:"recovery_code" form:"recovery_code"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" form:"password" binding:"required"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" form:"role" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" form:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" form:"token" binding:"required"`
+	NewPassword string `json:"new_password" form:"new_password" binding:"required,min=6"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" form:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" form:"new_password" binding:"required,min=6"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
 type LoginResponse struct {
 	Token string       `json:"token"`
 	User  UserResponse `json:"user"`
@@ -41,22 +308,54 @@ type UpdateProfileRequest struct {
 	Username *string `json:"username" form:"username"`
 	Bio      *string `json:"bio" form:"bio"`
 	Avatar   *string `json:"avatar"`
+	// akun private; saat diubah ke publik, follow request yang tertunda otomatis disetujui
+	IsPrivate   *bool   `json:"is_private" form:"is_private"`
+	DisplayName *string `json:"display_name" form:"display_name" binding:"omitempty,max=50"`
+	Location    *string `json:"location" form:"location" binding:"omitempty,max=100"`
+	// JSON array berisi {label, url}; array kosong menghapus semua link
+	Links *string `json:"links" form:"links"`
+	// format YYYY-MM-DD; string kosong menghapus tanggal lahir
+	Birthday           *string `json:"birthday" form:"birthday"`
+	BirthdayVisibility *string `json:"birthday_visibility" form:"birthday_visibility" binding:"omitempty,oneof=private month_day public"`
+	Banner             *string `json:"-"` // diisi dari file upload
 }
 
 type UserResponse struct {
-	ID             uint   `json:"id"`
-	Username       string `json:"username"`
-	Email          string `json:"email"`
-	Bio            string `json:"bio"`
-	Avatar         string `json:"avatar"`
-	FollowersCount int64  `json:"followers_count"`
-	FollowingCount int64  `json:"following_count"`
-	IsFollowed     bool   `json:"is_followed"`
-	Role           RoleType `json:"role"`
+	ID          uint          `json:"id"`
+	Username    string        `json:"username"`
+	Email       string        `json:"email"`
+	Bio         string        `json:"bio"`
+	Avatar      string        `json:"avatar"`
+	DisplayName string        `json:"display_name"`
+	Location    string        `json:"location"`
+	Links       []ProfileLink `json:"links"`
+	Banner      string        `json:"banner"`
+	// YYYY-MM-DD atau MM-DD tergantung birthday_visibility, kosong jika private
+	Birthday           string   `json:"birthday,omitempty"`
+	BirthdayVisibility string   `json:"birthday_visibility,omitempty"` // hanya untuk pemilik akun
+	FollowersCount     int64    `json:"followers_count"`
+	FollowingCount     int64    `json:"following_count"`
+	IsFollowed         bool     `json:"is_followed"`
+	IsRequested        bool     `json:"follow_requested"`
+	IsPrivate          bool     `json:"is_private"`
+	IsVerified         bool     `json:"is_verified"`
+	Role               RoleType `json:"role"`
+	// status akun berikut hanya untuk pemilik akun dan admin (ToOwnUserResponse,
+	// ToAdminUserResponse), tidak pernah untuk profil user lain
+	EmailVerified  *bool      `json:"email_verified,omitempty"`
+	TwoFactor      *bool      `json:"two_factor_enabled,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	Banned         bool       `json:"banned,omitempty"`
+	// terisi jika akun ditemukan lewat username lama; client sebaiknya
+	// mengarahkan ke username yang sekarang
+	RedirectedFrom string `json:"redirected_from,omitempty"`
+	// hanya terisi jika user sudah meminta penghapusan akun
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
 }
 
 type AuthorResponse struct {
-	ID       uint   `json:"id"`
-	Username string `json:"username"`
-	Avatar   string `json:"avatar"`
+	ID         uint   `json:"id"`
+	Username   string `json:"username"`
+	Avatar     string `json:"avatar"`
+	IsVerified bool   `json:"is_verified"`
 }
@@ -0,0 +1,239 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/oidc"
+	"go-sosmed/pkg/oidc/oidctest"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// fakeRepository menyimpan user dan identity di memory. Method Repository yang
+// tidak dipakai alur OIDC tidak diimplementasikan (panic jika terpanggil).
+type fakeRepository struct {
+	Repository
+	users      []*User
+	identities []*UserIdentity
+	reserved   map[string]bool
+}
+
+func (r *fakeRepository) FindIdentity(provider, subject string) (*UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRepository) FindByID(id uint) (*User, error) {
+	for _, u := range r.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRepository) FindByEmail(email string) (*User, error) {
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRepository) FindByUsername(username string) (*User, error) {
+	for _, u := range r.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRepository) IsUsernameReserved(username string, exceptUserID uint) (bool, error) {
+	return r.reserved[username], nil
+}
+
+func (r *fakeRepository) CreateIdentity(identity *UserIdentity) error {
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *fakeRepository) CreateWithIdentity(u *User, identity *UserIdentity) error {
+	u.ID = uint(len(r.users) + 1)
+	r.users = append(r.users, u)
+	identity.UserID = u.ID
+	return r.CreateIdentity(identity)
+}
+
+func (r *fakeRepository) Update(u *User, columns ...string) error {
+	return nil
+}
+
+// exchange menjalankan alur authorization code + PKCE terhadap mock provider
+// dan mengembalikan claims hasil verifikasi ID token
+func exchange(t *testing.T, claims map[string]interface{}) *oidc.Claims {
+	t.Helper()
+	server := oidctest.NewServer("go-sosmed-test")
+	t.Cleanup(server.Close)
+	provider := oidc.NewProviders(map[string]config.OIDCProviderConfig{
+		"mock": {Issuer: server.URL, ClientID: "go-sosmed-test", RedirectURL: "http://localhost/callback"},
+	})["mock"]
+
+	ctx := context.Background()
+	const verifier = "verifier-0123456789-0123456789-0123456789"
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, err := server.Authorize(authURL, oidctest.Grant{Claims: claims})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	result, err := provider.Exchange(ctx, code, verifier, "nonce")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	return result
+}
+
+func TestFindOrCreateOIDCUser(t *testing.T) {
+	tests := []struct {
+		name         string
+		claims       map[string]interface{}
+		users        []*User
+		identities   []*UserIdentity
+		wantErr      bool
+		wantUserID   uint
+		wantUsername string
+		wantVerified bool
+		wantLinked   bool
+	}{
+		{
+			name:       "existing identity logs in",
+			claims:     map[string]interface{}{"sub": "sub-1"},
+			users:      []*User{{ID: 7, Username: "alice", Email: "alice@example.com"}},
+			identities: []*UserIdentity{{Provider: "mock", Subject: "sub-1", UserID: 7}},
+			wantUserID: 7,
+		},
+		{
+			name:         "verified email links existing account",
+			claims:       map[string]interface{}{"sub": "sub-2", "email": "alice@example.com", "email_verified": true},
+			users:        []*User{{ID: 7, Username: "alice", Email: "alice@example.com"}},
+			wantUserID:   7,
+			wantVerified: true,
+			wantLinked:   true,
+		},
+		{
+			name:    "unverified email does not link existing account",
+			claims:  map[string]interface{}{"sub": "sub-3", "email": "alice@example.com", "email_verified": false},
+			users:   []*User{{ID: 7, Username: "alice", Email: "alice@example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing email is rejected",
+			claims:  map[string]interface{}{"sub": "sub-4"},
+			wantErr: true,
+		},
+		{
+			name:         "new account from preferred_username",
+			claims:       map[string]interface{}{"sub": "sub-5", "email": "bob@example.com", "email_verified": true, "preferred_username": "Bob.Smith"},
+			wantUserID:   1,
+			wantUsername: "bob_smith",
+			wantVerified: true,
+			wantLinked:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{users: tt.users, identities: tt.identities}
+			s := &service{repo: repo}
+
+			u, err := s.findOrCreateOIDCUser("mock", exchange(t, tt.claims))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if len(repo.identities) != len(tt.identities) {
+					t.Fatal("identity was linked despite the error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findOrCreateOIDCUser: %v", err)
+			}
+			if u.ID != tt.wantUserID {
+				t.Errorf("user ID = %d, want %d", u.ID, tt.wantUserID)
+			}
+			if tt.wantUsername != "" && u.Username != tt.wantUsername {
+				t.Errorf("username = %q, want %q", u.Username, tt.wantUsername)
+			}
+			if tt.wantVerified && u.EmailVerifiedAt == nil {
+				t.Error("email should be marked verified")
+			}
+			if tt.wantLinked {
+				if _, err := repo.FindIdentity("mock", tt.claims["sub"].(string)); err != nil {
+					t.Error("identity was not linked")
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   oidc.Claims
+		taken    []string
+		reserved []string
+		want     string // kosong berarti cukup dicek prefix dan panjangnya
+		prefix   string
+	}{
+		{name: "preferred username is normalized", claims: oidc.Claims{PreferredUsername: "Jane Doe!"}, want: "jane_doe"},
+		{name: "falls back to email local part", claims: oidc.Claims{PreferredUsername: "x", Email: "john.smith@example.com"}, want: "john_smith"},
+		{name: "falls back to name", claims: oidc.Claims{Email: "@example.com", Name: "Ann Lee"}, want: "ann_lee"},
+		{name: "falls back to user", claims: oidc.Claims{Email: "a@example.com"}, want: "user"},
+		{name: "long names are truncated", claims: oidc.Claims{PreferredUsername: "abcdefghijklmnopqrstuvwxyz"}, want: "abcdefghijklmnopqrst"},
+		{name: "taken username gets a suffix", claims: oidc.Claims{PreferredUsername: "jane"}, taken: []string{"jane"}, prefix: "jane_"},
+		{name: "reserved username gets a suffix", claims: oidc.Claims{PreferredUsername: "jane"}, reserved: []string{"jane"}, prefix: "jane_"},
+		{name: "suffix fits in the maximum length", claims: oidc.Claims{PreferredUsername: "abcdefghijklmnopqrstuvwxyz"}, taken: []string{"abcdefghijklmnopqrst"}, prefix: "abcdefghijklmno_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{reserved: map[string]bool{}}
+			for i, username := range tt.taken {
+				repo.users = append(repo.users, &User{ID: uint(i + 1), Username: username})
+			}
+			for _, username := range tt.reserved {
+				repo.reserved[username] = true
+			}
+			s := &service{repo: repo}
+
+			got, err := s.generateUsername(&tt.claims)
+			if err != nil {
+				t.Fatalf("generateUsername: %v", err)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tt.prefix != "" && !strings.HasPrefix(got, tt.prefix) {
+				t.Errorf("got %q, want prefix %q", got, tt.prefix)
+			}
+			if len(got) > usernameMaxLength {
+				t.Errorf("got %q, longer than %d", got, usernameMaxLength)
+			}
+			if _, err := repo.FindByUsername(got); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("got %q, which is already taken", got)
+			}
+		})
+	}
+}
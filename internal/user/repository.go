@@ -1,9 +1,15 @@
 package user
 
 import (
+	"errors"
+	"fmt"
+	"go-sosmed/internal/timeline"
+	"go-sosmed/pkg/pagination"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
@@ -14,21 +20,50 @@ type Repository interface {
 	SearchByUsername(
 		keyword string,
 		currentUserID uint,
-		limit int,
+		page pagination.Page,
 	) ([]*User, error)
 	FindByUsernameOrEmail(identifier string) (*User, error)
-	FindExploreUsers(currentUserID uint, limit, offset int) ([]User, error)
+	FindExploreUsers(currentUserID uint, page pagination.Page) ([]User, error)
 	FindUserDetailByUsername(username string, currentUserID uint) (*User, error)
 	FindCurrentUserDetail(currentUserID uint) (*User, error)
-	Update(user *User) error
+	Update(user *User, columns ...string) error
+	UpdateTokensRevokedAt(userID uint, revokedAt time.Time) error
+	UpdatePassword(userID uint, hashedPassword string) error
+	UpdateRole(userID uint, role RoleType, twoFactorRequired bool) error
+	UpdateDeletionScheduledAt(userID uint, scheduledAt *time.Time) error
+	CreateSuspension(suspension *UserSuspension) error
+	LiftSuspension(userID, actorID uint) (bool, error)
+	FindSuspensions(activeOnly bool, page pagination.Page) ([]*UserSuspension, error)
+	CreatePasswordResetToken(resetToken *PasswordResetToken) error
+	FindPasswordResetTokenByHash(hash string) (*PasswordResetToken, error)
+	ResetPassword(resetToken *PasswordResetToken, hashedPassword string) error
+	ConsumeTOTPStep(userID uint, step int64) (bool, error)
+	ReplaceRecoveryCodes(userID uint, codeHashes []string) error
+	UseRecoveryCode(userID uint, codeHash string) (bool, error)
+	DeleteRecoveryCodes(userID uint) error
+	FindIdentity(provider, subject string) (*UserIdentity, error)
+	FindLoginThrottles(keys []string) ([]LoginThrottle, error)
+	RecordLoginFailure(key string, window time.Duration) (*LoginThrottle, error)
+	LockLoginThrottle(key string, until time.Time) error
+	DeleteLoginThrottle(key string) error
+	CreateIdentity(identity *UserIdentity) error
+	CreateWithIdentity(user *User, identity *UserIdentity) error
 	FindFollowingUsers(
+		userID uint,
 		currentUserID uint,
-		limit, offset int,
+		page pagination.Page,
 	) ([]*User, error)
 	FindFollowerByUsers(
+		userID uint,
 		currentUserID uint,
-		limit, offset int,
+		page pagination.Page,
 	) ([]*User, error)
+	IsFollowing(followerID, followingID uint) (bool, error)
+	AcceptPendingFollowRequests(userID uint) error
+	ChangeUsername(user *User, history *UsernameHistory, columns ...string) error
+	FindUsernameHistory(username string) (*UsernameHistory, error)
+	FindUsernameHistoryByUser(userID uint) ([]UsernameHistory, error)
+	IsUsernameReserved(username string, exceptUserID uint) (bool, error)
 }
 
 type repository struct {
@@ -41,11 +76,6 @@ func (r *repository) FindCurrentUserDetail(currentUserID uint) (*User, error) {
 
 	err := r.db.
 		Table("users").
-		Select(`
-			users.*,
-			(SELECT COUNT(*) FROM follows WHERE follows.following_id = users.id) AS followers_count,
-			(SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id) AS following_count
-		`).
 		Where("users.id = ?", currentUserID).
 		First(&user).Error
 
@@ -60,7 +90,7 @@ func (r *repository) FindCurrentUserDetail(currentUserID uint) (*User, error) {
 func (r *repository) SearchByUsername(
 	keyword string,
 	currentUserID uint,
-	limit int,
+	page pagination.Page,
 ) ([]*User, error) {
 
 	var users []*User
@@ -74,13 +104,21 @@ func (r *repository) SearchByUsername(
 				FROM follows
 				WHERE follows.follower_id = ?
 				AND follows.following_id = users.id
+				AND follows.status = 'accepted'
 			) AS is_followed,
-			(SELECT COUNT(*) FROM follows WHERE follows.following_id = users.id) AS followers_count,
-			(SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id) AS following_count
-		`, currentUserID).
+			EXISTS (
+				SELECT 1 FROM follows
+				WHERE follows.follower_id = ?
+				AND follows.following_id = users.id
+				AND follows.status = 'pending'
+			) AS is_requested
+		`, currentUserID, currentUserID).
 		Where("LOWER(users.username) LIKE ?", "%"+strings.ToLower(keyword)+"%").
+		Where("users.id != ?", currentUserID).
+		Where("users.purged_at IS NULL").
 		Where("users.role != ?", "admin").
-		Limit(limit).
+		// users tidak punya created_at, urutan memakai id
+		Scopes(NotBlocked("users.id", currentUserID), page.ByID("users.id")).
 		Find(&users).Error
 
 	if err != nil {
@@ -91,24 +129,29 @@ func (r *repository) SearchByUsername(
 }
 
 // FindExploreUsers implements Repository.
-func (r *repository) FindExploreUsers(currentUserID uint, limit int, offset int) ([]User, error) {
+func (r *repository) FindExploreUsers(currentUserID uint, page pagination.Page) ([]User, error) {
 	var users []User
 	err := r.db.
 		Model(&User{}).
 		Select(`
 			users.*,
-			(SELECT COUNT(*) FROM follows WHERE follows.following_id = users.id) AS followers_count,
-			(SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id) AS following_count,
 			EXISTS (
 				SELECT 1 FROM follows
 				WHERE follows.follower_id = ?
 				AND follows.following_id = users.id
-			) AS is_followed
-		`, currentUserID).
+				AND follows.status = 'accepted'
+			) AS is_followed,
+			EXISTS (
+				SELECT 1 FROM follows
+				WHERE follows.follower_id = ?
+				AND follows.following_id = users.id
+				AND follows.status = 'pending'
+			) AS is_requested
+		`, currentUserID, currentUserID).
 		Where("users.id != ?", currentUserID).
+		Where("users.purged_at IS NULL").
 		Where("users.role != ?", "admin").
-		Limit(limit).
-		Offset(offset).
+		Scopes(NotBlocked("users.id", currentUserID), page.ByID("users.id")).
 		Find(&users).Error
 	if err != nil {
 		return nil, err
@@ -159,8 +202,315 @@ func (r *repository) FindByID(id uint) (*User, error) {
 }
 
 // Update implements Repository.
-func (r *repository) Update(user *User) error {
-	return r.db.Save(user).Error
+// Hanya kolom yang disebutkan yang ditulis, agar perubahan lain yang terjadi
+// bersamaan (ban, suspensi, logout dari semua perangkat, verifikasi akun)
+// tidak tertimpa nilai lama dari baris yang dibaca sebelumnya.
+func (r *repository) Update(user *User, columns ...string) error {
+	if len(columns) == 0 {
+		return errors.New("no columns to update")
+	}
+	return r.db.Model(user).Select(columns).Updates(user).Error
+}
+
+// ChangeUsername implements Repository.
+// Perubahan profil (columns), username baru dan riwayat username lama disimpan
+// dalam satu transaksi.
+func (r *repository) ChangeUsername(user *User, history *UsernameHistory, columns ...string) error {
+	// badge verifikasi diberikan untuk username lama, user harus mengajukan ulang
+	user.VerifiedAt = nil
+	columns = append(columns, "username", "username_changed_at", "verified_at")
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Select(columns).Updates(user).Error; err != nil {
+			return err
+		}
+		return tx.Create(history).Error
+	})
+}
+
+// FindUsernameHistory implements Repository.
+// Mengembalikan pemakai terakhir sebuah username lama.
+func (r *repository) FindUsernameHistory(username string) (*UsernameHistory, error) {
+	var history UsernameHistory
+	err := r.db.
+		Where("username = ?", username).
+		Order("created_at DESC, id DESC").
+		First(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// FindUsernameHistoryByUser implements Repository.
+func (r *repository) FindUsernameHistoryByUser(userID uint) ([]UsernameHistory, error) {
+	var histories []UsernameHistory
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&histories).Error
+	return histories, err
+}
+
+// IsUsernameReserved implements Repository.
+// Username lama milik exceptUserID sendiri selalu boleh dipakai kembali.
+func (r *repository) IsUsernameReserved(username string, exceptUserID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&UsernameHistory{}).
+		Where("username = ? AND user_id <> ? AND reserved_until > ?", username, exceptUserID, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateTokensRevokedAt implements Repository.
+func (r *repository) UpdateTokensRevokedAt(userID uint, revokedAt time.Time) error {
+	return r.db.Model(&User{}).Where("id = ?", userID).Update("tokens_revoked_at", revokedAt).Error
+}
+
+// UpdatePassword implements Repository.
+func (r *repository) UpdatePassword(userID uint, hashedPassword string) error {
+	return r.db.Model(&User{}).Where("id = ?", userID).Update("password", hashedPassword).Error
+}
+
+// UpdateRole implements Repository.
+func (r *repository) UpdateRole(userID uint, role RoleType, twoFactorRequired bool) error {
+	return r.db.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"role":                role,
+		"two_factor_required": twoFactorRequired,
+	}).Error
+}
+
+// UpdateDeletionScheduledAt implements Repository.
+func (r *repository) UpdateDeletionScheduledAt(userID uint, scheduledAt *time.Time) error {
+	return r.db.Model(&User{}).Where("id = ?", userID).Update("deletion_scheduled_at", scheduledAt).Error
+}
+
+// CreateSuspension implements Repository.
+// Riwayat suspend/ban dicatat dan status di tabel users diperbarui dalam satu transaksi.
+// Suspend/ban yang masih aktif sebelumnya dianggap digantikan.
+func (r *repository) CreateSuspension(suspension *UserSuspension) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&UserSuspension{}).
+			Where("user_id = ? AND lifted_at IS NULL", suspension.UserID).
+			Updates(map[string]interface{}{"lifted_at": suspension.StartsAt, "lifted_by_id": suspension.ActorID}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(suspension).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"suspension_reason": suspension.Reason,
+			"suspended_until":   suspension.EndsAt,
+			"banned_at":         nil,
+		}
+		if suspension.Type == SuspensionTypeBan {
+			updates["suspended_until"] = nil
+			updates["banned_at"] = suspension.StartsAt
+		}
+		return tx.Model(&User{}).Where("id = ?", suspension.UserID).Updates(updates).Error
+	})
+}
+
+// LiftSuspension implements Repository.
+// Mengembalikan false jika user tidak sedang di-suspend atau di-ban.
+func (r *repository) LiftSuspension(userID, actorID uint) (bool, error) {
+	lifted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&User{}).
+			Where("id = ? AND (banned_at IS NOT NULL OR suspended_until > ?)", userID, now).
+			Updates(map[string]interface{}{
+				"suspended_until":   nil,
+				"banned_at":         nil,
+				"suspension_reason": "",
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		lifted = true
+		return tx.Model(&UserSuspension{}).
+			Where("user_id = ? AND lifted_at IS NULL", userID).
+			Updates(map[string]interface{}{"lifted_at": now, "lifted_by_id": actorID}).Error
+	})
+	return lifted, err
+}
+
+// FindSuspensions implements Repository.
+func (r *repository) FindSuspensions(activeOnly bool, page pagination.Page) ([]*UserSuspension, error) {
+	var suspensions []*UserSuspension
+	query := r.db.Preload("User").Scopes(page.Newest("user_suspensions.created_at", "user_suspensions.id"))
+	if activeOnly {
+		query = query.Where("lifted_at IS NULL AND (ends_at IS NULL OR ends_at > ?)", time.Now())
+	}
+	if err := query.Find(&suspensions).Error; err != nil {
+		return nil, err
+	}
+	return suspensions, nil
+}
+
+// CreatePasswordResetToken implements Repository.
+func (r *repository) CreatePasswordResetToken(resetToken *PasswordResetToken) error {
+	return r.db.Create(resetToken).Error
+}
+
+// FindPasswordResetTokenByHash implements Repository.
+func (r *repository) FindPasswordResetTokenByHash(hash string) (*PasswordResetToken, error) {
+	var resetToken PasswordResetToken
+	if err := r.db.Where("token_hash = ?", hash).First(&resetToken).Error; err != nil {
+		return nil, err
+	}
+	return &resetToken, nil
+}
+
+// ResetPassword implements Repository.
+// Token ditandai terpakai (hanya sekali) dan password diganti dalam satu transaksi.
+// Token reset lain milik user yang masih aktif ikut dinonaktifkan.
+func (r *repository) ResetPassword(resetToken *PasswordResetToken, hashedPassword string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("reset token already used")
+		}
+
+		if err := tx.Model(&PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", resetToken.UserID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&User{}).
+			Where("id = ?", resetToken.UserID).
+			Update("password", hashedPassword).Error
+	})
+}
+
+// ConsumeTOTPStep implements Repository.
+// Menyimpan time-step terakhir; gagal jika step yang sama (atau lebih lama) sudah dipakai.
+func (r *repository) ConsumeTOTPStep(userID uint, step int64) (bool, error) {
+	result := r.db.Model(&User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReplaceRecoveryCodes implements Repository.
+func (r *repository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, RecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode implements Repository.
+func (r *repository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := r.db.Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteRecoveryCodes implements Repository.
+func (r *repository) DeleteRecoveryCodes(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error
+}
+
+// FindLoginThrottles implements Repository.
+func (r *repository) FindLoginThrottles(keys []string) ([]LoginThrottle, error) {
+	var throttles []LoginThrottle
+	if err := r.db.Where("throttle_key IN ?", keys).Find(&throttles).Error; err != nil {
+		return nil, err
+	}
+	return throttles, nil
+}
+
+// RecordLoginFailure implements Repository.
+// Menambah counter gagal login; counter dimulai ulang jika percobaan terakhir sudah
+// lebih lama dari window dan tidak sedang dikunci.
+func (r *repository) RecordLoginFailure(key string, window time.Duration) (*LoginThrottle, error) {
+	var throttle LoginThrottle
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("throttle_key = ?", key).
+			First(&throttle).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			throttle = LoginThrottle{Key: key, FailedCount: 1, LastFailedAt: now}
+			return tx.Create(&throttle).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		locked := throttle.LockedUntil != nil && throttle.LockedUntil.After(now)
+		if !locked && now.Sub(throttle.LastFailedAt) > window {
+			throttle.FailedCount = 0
+			throttle.LockedUntil = nil
+		}
+		throttle.FailedCount++
+		throttle.LastFailedAt = now
+		return tx.Save(&throttle).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// LockLoginThrottle implements Repository.
+func (r *repository) LockLoginThrottle(key string, until time.Time) error {
+	return r.db.Model(&LoginThrottle{}).Where("throttle_key = ?", key).Update("locked_until", until).Error
+}
+
+// DeleteLoginThrottle implements Repository.
+func (r *repository) DeleteLoginThrottle(key string) error {
+	return r.db.Where("throttle_key = ?", key).Delete(&LoginThrottle{}).Error
+}
+
+// FindIdentity implements Repository.
+func (r *repository) FindIdentity(provider, subject string) (*UserIdentity, error) {
+	var identity UserIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateIdentity implements Repository.
+func (r *repository) CreateIdentity(identity *UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// CreateWithIdentity implements Repository.
+// User baru dan identity-nya dibuat dalam satu transaksi.
+func (r *repository) CreateWithIdentity(user *User, identity *UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
 }
 
 // FindUserDetailByID implements Repository.
@@ -175,15 +525,21 @@ func (r *repository) FindUserDetailByUsername(
 		Table("users").
 		Select(`
 			users.*,
-			(SELECT COUNT(*) FROM follows WHERE follows.following_id = users.id) AS followers_count,
-			(SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id) AS following_count,
 			EXISTS (
 				SELECT 1 FROM follows
 				WHERE follows.follower_id = ?
 				AND follows.following_id = users.id
-			) AS is_followed
-		`, currentUserID).
+				AND follows.status = 'accepted'
+			) AS is_followed,
+			EXISTS (
+				SELECT 1 FROM follows
+				WHERE follows.follower_id = ?
+				AND follows.following_id = users.id
+				AND follows.status = 'pending'
+			) AS is_requested
+		`, currentUserID, currentUserID).
 		Where("users.username = ?", username).
+		Scopes(NotBlocked("users.id", currentUserID)).
 		First(&user).Error
 
 	if err != nil {
@@ -193,9 +549,12 @@ func (r *repository) FindUserDetailByUsername(
 	return &user, nil
 }
 
+// FindFollowingUsers implements Repository.
+// is_followed dihitung dari sudut pandang currentUserID, bukan pemilik daftar.
 func (r *repository) FindFollowingUsers(
+	userID uint,
 	currentUserID uint,
-	limit, offset int,
+	page pagination.Page,
 ) ([]*User, error) {
 
 	var users []*User
@@ -204,18 +563,23 @@ func (r *repository) FindFollowingUsers(
 		Table("users").
 		Select(`
 			users.*,
-			TRUE AS is_followed,
-			(SELECT COUNT(*) FROM follows WHERE follows.following_id = users.id) AS followers_count,
-			(SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id) AS following_count
-		`).
+			EXISTS (
+				SELECT 1 FROM follows AS viewer_follows
+				WHERE viewer_follows.follower_id = ?
+				AND viewer_follows.following_id = users.id
+				AND viewer_follows.status = 'accepted'
+			) AS is_followed,
+			follows.id AS follow_id
+		`, currentUserID).
 		Joins(`
 			JOIN follows 
 			ON follows.following_id = users.id
 			AND follows.follower_id = ?
-		`, currentUserID).
+			AND follows.status = 'accepted'
+		`, userID).
 		Where("users.role != ?", "admin").
-		Limit(limit).
-		Offset(offset).
+		// follow terbaru lebih dulu; follows.created_at kosong untuk data lama, jadi memakai id
+		Scopes(page.ByID("follows.id")).
 		Find(&users).Error
 
 	if err != nil {
@@ -225,9 +589,12 @@ func (r *repository) FindFollowingUsers(
 	return users, nil
 }
 
+// FindFollowerByUsers implements Repository.
+// is_followed dihitung dari sudut pandang currentUserID, bukan pemilik daftar.
 func (r *repository) FindFollowerByUsers(
+	userID uint,
 	currentUserID uint,
-	limit, offset int,
+	page pagination.Page,
 ) ([]*User, error) {
 
 	var users []*User
@@ -236,18 +603,23 @@ func (r *repository) FindFollowerByUsers(
 		Table("users").
 		Select(`
 			users.*,
-			TRUE AS is_followed,
-			(SELECT COUNT(*) FROM follows WHERE follows.following_id = users.id) AS followers_count,
-			(SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id) AS following_count
-		`).
+			EXISTS (
+				SELECT 1 FROM follows AS viewer_follows
+				WHERE viewer_follows.follower_id = ?
+				AND viewer_follows.following_id = users.id
+				AND viewer_follows.status = 'accepted'
+			) AS is_followed,
+			follows.id AS follow_id
+		`, currentUserID).
 		Joins(`
 			JOIN follows 
 			ON follows.follower_id = users.id
 			AND follows.following_id = ?
-		`, currentUserID).
+			AND follows.status = 'accepted'
+		`, userID).
 		Where("users.role != ?", "admin").
-		Limit(limit).
-		Offset(offset).
+		// follow terbaru lebih dulu; follows.created_at kosong untuk data lama, jadi memakai id
+		Scopes(page.ByID("follows.id")).
 		Find(&users).Error
 
 	if err != nil {
@@ -257,6 +629,44 @@ func (r *repository) FindFollowerByUsers(
 	return users, nil
 }
 
+// IsFollowing implements Repository.
+// Hanya follow yang sudah disetujui yang dihitung.
+func (r *repository) IsFollowing(followerID, followingID uint) (bool, error) {
+	return IsAcceptedFollower(r.db, followerID, followingID)
+}
+
+// AcceptPendingFollowRequests implements Repository.
+// Counter follow dan timeline setiap follower yang baru disetujui ikut diisi.
+func (r *repository) AcceptPendingFollowRequests(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var followerIDs []uint
+		err := tx.Table("follows").
+			Where("following_id = ? AND status = ?", userID, FollowStatusPending).
+			Pluck("follower_id", &followerIDs).Error
+		if err != nil {
+			return err
+		}
+		if len(followerIDs) == 0 {
+			return nil
+		}
+		err = tx.Table("follows").
+			Where("following_id = ? AND status = ?", userID, FollowStatusPending).
+			Update("status", FollowStatusAccepted).Error
+		if err != nil {
+			return err
+		}
+		for _, followerID := range followerIDs {
+			if err := AdjustFollowCounts(tx, followerID, userID, 1); err != nil {
+				return err
+			}
+			if err := timeline.Backfill(tx, followerID, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
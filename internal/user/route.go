@@ -3,6 +3,7 @@ package user
 import (
 	"go-sosmed/pkg/config"
 	"go-sosmed/pkg/middlewares"
+	"go-sosmed/pkg/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,16 +12,46 @@ func SetupRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
 	publicAPI := r.Group("/api")
 	publicAPI.POST("/register", ctrl.Register)
 	publicAPI.POST("/login", ctrl.Login)
+	publicAPI.POST("/login/2fa", ctrl.VerifyTwoFactorLogin)
+	publicAPI.GET("/auth/oidc/:provider/login", ctrl.OIDCLogin)
+	publicAPI.GET("/auth/oidc/:provider/callback", ctrl.OIDCCallback)
+	publicAPI.POST("/token/refresh", ctrl.RefreshToken)
+	publicAPI.GET("/email/verify", ctrl.VerifyEmail)
+	publicAPI.POST("/email/verify", ctrl.VerifyEmail)
+	publicAPI.POST("/password/forgot", ctrl.ForgotPassword)
+	publicAPI.POST("/password/reset", ctrl.ResetPassword)
 
 	protectedAPI := r.Group("/api")
 	protectedAPI.Use(middlewares.Authenticate(cfg))
-	protectedAPI.PUT("/users/me", middlewares.UploadAvatar(), ctrl.UpdateProfile)
-	protectedAPI.GET("/users/me", ctrl.GetCurrentUser)
-	protectedAPI.GET("/users/username/:username", ctrl.GetUserDetailByUsername)
+	protectedAPI.PUT("/users/me", middlewares.RequireScope(middlewares.ScopeProfileWrite), middlewares.UploadAvatar(), middlewares.UploadBanner(), ctrl.UpdateProfile)
+	protectedAPI.GET("/users/me", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetCurrentUser)
+	protectedAPI.GET("/users/username/:username", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetUserDetailByUsername)
+	protectedAPI.GET("/users/username/:username/followers", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetFollowersByUsername)
+	protectedAPI.GET("/users/username/:username/followings", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetFollowingsByUsername)
 
-	protectedAPI.GET("/users/explore", ctrl.GetExploreUsers)
-	protectedAPI.GET("/users/search", ctrl.SearchUser)
-	protectedAPI.GET("/users/followers", ctrl.GetUserFollowers)
-	protectedAPI.GET("/users/followings", ctrl.GetUserFollowings)
-	protectedAPI.POST("/logout", ctrl.Logout)
+	protectedAPI.GET("/users/explore", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetExploreUsers)
+	protectedAPI.GET("/users/search", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.SearchUser)
+	protectedAPI.GET("/users/followers", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetUserFollowers)
+	protectedAPI.GET("/users/followings", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetUserFollowings)
+
+	// pengelolaan akun hanya dari login biasa, bukan personal access token
+	accountAPI := r.Group("/api")
+	accountAPI.Use(middlewares.Authenticate(cfg), middlewares.RequireSessionAuth())
+	accountAPI.DELETE("/users/me", ctrl.DeleteAccount)
+	accountAPI.PUT("/users/me/password", ctrl.ChangePassword)
+	accountAPI.POST("/users/me/2fa/enroll", ctrl.EnrollTwoFactor)
+	accountAPI.POST("/users/me/2fa/confirm", ctrl.ConfirmTwoFactor)
+	accountAPI.POST("/users/me/2fa/disable", ctrl.DisableTwoFactor)
+	accountAPI.POST("/logout", ctrl.Logout)
+	accountAPI.POST("/logout/all", ctrl.LogoutAll)
+	accountAPI.POST("/email/verify/resend", ctrl.ResendVerificationEmail)
+
+	adminAPI := r.Group("/api/admin")
+	adminAPI.Use(middlewares.Authenticate(cfg), middlewares.RequireSessionAuth(), middlewares.RequireTwoFactor())
+	adminAPI.POST("/users/:user_id/unlock", middlewares.RequirePermission(rbac.PermUserUnlock), ctrl.UnlockAccount)
+	adminAPI.PUT("/users/:user_id/role", middlewares.RequirePermission(rbac.PermUserAssignRole), ctrl.AssignRole)
+	adminAPI.POST("/users/:user_id/suspend", middlewares.RequirePermission(rbac.PermUserSuspend), ctrl.SuspendUser)
+	adminAPI.POST("/users/:user_id/ban", middlewares.RequirePermission(rbac.PermUserBan), ctrl.BanUser)
+	adminAPI.DELETE("/users/:user_id/suspension", middlewares.RequirePermission(rbac.PermUserSuspend), ctrl.LiftSuspension)
+	adminAPI.GET("/suspensions", middlewares.RequirePermission(rbac.PermUserSuspend), ctrl.GetSuspensions)
 }
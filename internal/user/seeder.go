@@ -3,6 +3,7 @@ package user
 import (
 	"go-sosmed/pkg/config"
 	"log"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -15,14 +16,18 @@ func HashPassword(password string) string {
 	return string(hashed)
 }
 
-func SeedAdminUser() {
+func SeedAdminUser(cfg *config.Config) {
 	db := config.GetDB()
+	require2FA := cfg.AdminRequire2FA == "true"
 
+	verifiedAt := time.Now()
 	admin := User{
-		Username: "admin",
-		Email:    "admin@email.com",
-		Password: HashPassword("adminadmin"),
-		Role:     RoleAdmin,
+		Username:          "admin",
+		Email:             "admin@email.com",
+		Password:          HashPassword("adminadmin"),
+		Role:              RoleAdmin,
+		EmailVerifiedAt:   &verifiedAt,
+		TwoFactorRequired: require2FA,
 	}
 
 	var count int64
@@ -36,5 +41,11 @@ func SeedAdminUser() {
 		}
 	} else {
 		log.Println("User admin sudah ada.")
+		// Sinkronkan kewajiban 2FA admin dengan konfigurasi
+		db.Model(&User{}).Where("username = ? OR email = ?", admin.Username, admin.Email).
+			Update("two_factor_required", require2FA)
+		// admin yang dibuat sebelum ada verifikasi email tetap bisa menulis
+		db.Model(&User{}).Where("(username = ? OR email = ?) AND email_verified_at IS NULL", admin.Username, admin.Email).
+			Update("email_verified_at", verifiedAt)
 	}
 }
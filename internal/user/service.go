@@ -1,9 +1,25 @@
 package user
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
 	"fmt"
+	"go-sosmed/internal/token"
 	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/jwtkeys"
+	"go-sosmed/pkg/mailer"
+	"go-sosmed/pkg/oidc"
+	"go-sosmed/pkg/pagination"
+	"go-sosmed/pkg/rbac"
+	"go-sosmed/pkg/totp"
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,29 +28,278 @@ import (
 
 type Service interface {
 	Register(req *RegisterRequest) (*UserResponse, error)
-	Login(req *LoginRequest) (string, *UserResponse, error)
+	Login(req *LoginRequest, client ClientInfo) (*LoginResult, error)
+	VerifyTwoFactorLogin(req *TwoFactorLoginRequest, client ClientInfo) (*AuthTokens, *UserResponse, error)
+	EnrollTwoFactor(userID uint) (*TwoFactorEnrollResponse, error)
+	ConfirmTwoFactor(userID uint, code string) (*RecoveryCodesResponse, error)
+	DisableTwoFactor(userID uint, req *TwoFactorDisableRequest) error
+	RefreshToken(refreshToken string) (*AuthTokens, error)
 	UpdateProfile(userID uint, req *UpdateProfileRequest) (*UserResponse, error)
 	GetUserByID(userID uint) (*UserResponse, error)
-	GetExploreUsers(currentUserID uint, limit, offset int) ([]UserResponse, error)
+	GetExploreUsers(currentUserID uint, page pagination.Page) ([]UserResponse, string, error)
 	GetUserDetailByUsername(username string, currentUserID uint) (*UserResponse, error)
 	GenerateToken(user *User) (string, error)
 	GetUserByUsername(username string) (*UserResponse, error)
-	SearchUser(keyword string, currentUserID uint) ([]*UserResponse, error)
+	SearchUser(keyword string, currentUserID uint, page pagination.Page) ([]*UserResponse, string, error)
 	GetCurrentUserDetail(currentUserID uint) (*UserResponse, error)
 	GetUserFollowers(
 		currentUserID uint,
-		limit, offset int,
-	) ([]*UserResponse, error)
+		page pagination.Page,
+	) ([]*UserResponse, string, error)
 	GetUserFollowings(
 		currentUserID uint,
-		limit, offset int,
-	) ([]*UserResponse, error)
-	Logout() error
+		page pagination.Page,
+	) ([]*UserResponse, string, error)
+	GetFollowersByUsername(username string, currentUserID uint, page pagination.Page) ([]*UserResponse, string, error)
+	GetFollowingsByUsername(username string, currentUserID uint, page pagination.Page) ([]*UserResponse, string, error)
+	Logout(userID, sessionID uint, tokenID string, tokenExpiresAt time.Time, refreshToken string) error
+	LogoutAll(userID uint) error
+	VerifyEmail(verificationToken string) error
+	ResendVerificationEmail(userID uint) error
+	ForgotPassword(req *ForgotPasswordRequest) error
+	ResetPassword(req *ResetPasswordRequest) error
+	ChangePassword(userID, sessionID uint, req *ChangePasswordRequest) error
+	ScheduleAccountDeletion(userID uint, req *DeleteAccountRequest) (*time.Time, error)
+	UnlockAccount(userID uint) error
+	AssignRole(actorID, userID uint, role RoleType) (*UserResponse, error)
+	SuspendUser(actorID uint, actorRole string, userID uint, req *SuspendUserRequest) (*SuspensionResponse, error)
+	BanUser(actorID uint, actorRole string, userID uint, req *BanUserRequest) (*SuspensionResponse, error)
+	LiftSuspension(actorID uint, actorRole string, userID uint) error
+	GetSuspensions(activeOnly bool, page pagination.Page) ([]*SuspensionResponse, string, error)
+	StartOIDCLogin(ctx context.Context, providerName string) (authURL string, stateToken string, err error)
+	CompleteOIDCLogin(ctx context.Context, providerName string, req *OIDCCallbackRequest, stateToken string, client ClientInfo) (*LoginResult, error)
 }
 
 type service struct {
-	repo Repository
-	cfg  *config.Config
+	repo         Repository
+	tokenService token.Service
+	mailer       mailer.Mailer
+	providers    map[string]*oidc.Provider
+	cfg          *config.Config
+}
+
+const (
+	purposeEmailVerification = "email_verification"
+	purposeTwoFactorLogin    = "two_factor_challenge"
+	purposeOIDCState         = "oidc_state"
+
+	twoFactorChallengeTTL = 5 * time.Minute
+	oidcStateTTL          = 10 * time.Minute
+	recoveryCodeCount     = 10
+)
+
+// purposeClaims adalah JWT berumur pendek untuk keperluan selain autentikasi
+// (misal link verifikasi email). Purpose wajib dicek agar token tidak tertukar.
+type purposeClaims struct {
+	Purpose string `json:"purpose"`
+	Email   string `json:"email,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func (s *service) signPurposeToken(user *User, purpose string, ttl time.Duration) (string, error) {
+	claims := purposeClaims{
+		Purpose: purpose,
+		Email:   user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return jwtkeys.Get().Sign(claims, "")
+}
+
+func (s *service) parsePurposeToken(raw, purpose string) (uint, *purposeClaims, error) {
+	claims := &purposeClaims{}
+	keyring := jwtkeys.Get()
+	parsed, err := jwt.ParseWithClaims(raw, claims, keyring.Keyfunc, jwt.WithValidMethods(keyring.ValidMethods()))
+	if err != nil || !parsed.Valid || claims.Purpose != purpose {
+		return 0, nil, fmt.Errorf("invalid or expired token")
+	}
+	id, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid or expired token")
+	}
+	return uint(id), claims, nil
+}
+
+// sendVerificationEmail mengirim link verifikasi yang ditandatangani ke email user
+func (s *service) sendVerificationEmail(user *User) error {
+	ttl, err := time.ParseDuration(s.cfg.EmailVerificationExpires)
+	if err != nil {
+		ttl = 24 * time.Hour
+	}
+	verificationToken, err := s.signPurposeToken(user, purposeEmailVerification, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	link := strings.TrimRight(s.cfg.AppURL, "/") + "/api/email/verify?token=" + url.QueryEscape(verificationToken)
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThis link expires in %s.\n",
+			user.Username, link, ttl,
+		),
+	})
+}
+
+// ForgotPassword implements Service.
+// Selalu sukses dari sisi pemanggil agar tidak bisa dipakai untuk menebak email terdaftar.
+func (s *service) ForgotPassword(req *ForgotPasswordRequest) error {
+	user, err := s.repo.FindByEmail(req.Email)
+	if err != nil {
+		return nil
+	}
+
+	ttl, err := time.ParseDuration(s.cfg.PasswordResetExpires)
+	if err != nil {
+		ttl = time.Hour
+	}
+	raw, err := token.GenerateRandomString(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	if err := s.repo.CreatePasswordResetToken(&PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: token.HashToken(raw),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return fmt.Errorf("failed to create reset token: %w", err)
+	}
+
+	link := strings.TrimRight(s.cfg.CorsOrigin, "/") + "/reset-password?token=" + url.QueryEscape(raw)
+	if err := s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Use the link below to choose a new one:\n\n%s\n\nThis link expires in %s and can only be used once. If you did not request this, you can ignore this email.\n",
+			user.Username, link, ttl,
+		),
+	}); err != nil {
+		log.Printf("Warning: failed to send password reset email: %v", err)
+	}
+	return nil
+}
+
+// ResetPassword implements Service.
+func (s *service) ResetPassword(req *ResetPasswordRequest) error {
+	resetToken, err := s.repo.FindPasswordResetTokenByHash(token.HashToken(req.Token))
+	if err != nil || resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.repo.ResetPassword(resetToken, string(hashedPassword)); err != nil {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+
+	// Semua session lama tidak berlaku lagi setelah password direset
+	return s.LogoutAll(resetToken.UserID)
+}
+
+// ChangePassword implements Service.
+// Session lain dicabut, session yang sedang dipakai tetap aktif.
+func (s *service) ChangePassword(userID, sessionID uint, req *ChangePasswordRequest) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return fmt.Errorf("current password is incorrect")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.repo.UpdatePassword(userID, string(hashedPassword)); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if sessionID == 0 {
+		return s.LogoutAll(userID)
+	}
+	return s.tokenService.RevokeOtherSessions(userID, sessionID)
+}
+
+// ScheduleAccountDeletion implements Service.
+// Akun baru dihapus setelah masa tenggang; semua session dicabut sehingga login
+// berikutnya (yang membatalkan penghapusan) harus dilakukan secara sadar oleh user.
+func (s *service) ScheduleAccountDeletion(userID uint, req *DeleteAccountRequest) (*time.Time, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return nil, fmt.Errorf("password is incorrect")
+	}
+
+	scheduledAt := time.Now().Add(durationOrDefault(s.cfg.AccountDeletionGracePeriod, 30*24*time.Hour))
+	if err := s.repo.UpdateDeletionScheduledAt(user.ID, &scheduledAt); err != nil {
+		return nil, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+	if err := s.LogoutAll(user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Your account is scheduled for deletion",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour account and all of its content will be permanently deleted on %s.\n\nIf you change your mind, simply sign in again before then to cancel the deletion.\n",
+			user.Username, scheduledAt.UTC().Format(time.RFC1123),
+		),
+	}); err != nil {
+		log.Printf("Warning: failed to send account deletion email: %v", err)
+	}
+	return &scheduledAt, nil
+}
+
+// VerifyEmail implements Service.
+func (s *service) VerifyEmail(verificationToken string) error {
+	userID, claims, err := s.parsePurposeToken(verificationToken, purposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("invalid or expired token")
+	}
+	// link lama tidak berlaku jika email sudah diganti
+	if !strings.EqualFold(user.Email, claims.Email) {
+		return fmt.Errorf("invalid or expired token")
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := s.repo.Update(user, "email_verified_at"); err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+	return nil
+}
+
+// ResendVerificationEmail implements Service.
+func (s *service) ResendVerificationEmail(userID uint) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if user.EmailVerifiedAt != nil {
+		return fmt.Errorf("email already verified")
+	}
+	if err := s.sendVerificationEmail(user); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
 }
 
 // GetCurrentUserDetail implements Service.
@@ -43,92 +308,239 @@ func (s *service) GetCurrentUserDetail(currentUserID uint) (*UserResponse, error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current user detail: %w", err)
 	}
-	return ToUserResponse(user), nil
+	return ToOwnUserResponse(user), nil
 }
 
 // Logout implements Service.
-func (s *service) Logout() error {
+// Access token yang sedang dipakai masuk denylist, lalu session dan refresh token-nya dicabut.
+func (s *service) Logout(userID, sessionID uint, tokenID string, tokenExpiresAt time.Time, refreshToken string) error {
+	if err := s.tokenService.RevokeAccessToken(tokenID, userID, tokenExpiresAt); err != nil {
+		return err
+	}
+	if sessionID != 0 {
+		if err := s.tokenService.RevokeSession(userID, sessionID); err != nil && !errors.Is(err, token.ErrSessionNotFound) {
+			return err
+		}
+	}
+	return s.tokenService.RevokeRefreshToken(refreshToken)
+}
+
+// LogoutAll implements Service.
+// Semua refresh token dicabut dan semua access token yang terbit sebelum saat ini ditolak.
+func (s *service) LogoutAll(userID uint) error {
+	if err := s.tokenService.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateTokensRevokedAt(userID, time.Now().Truncate(time.Second)); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
 	return nil
 }
 
-// GetUserFollowers implements Service.
-func (s *service) GetUserFollowers(currentUserID uint, limit int, offset int) ([]*UserResponse, error) {
-	followers, err := s.repo.FindFollowerByUsers(currentUserID, limit, offset)
+// RefreshToken implements Service.
+func (s *service) RefreshToken(refreshToken string) (*AuthTokens, error) {
+	raw, refresh, err := s.tokenService.RotateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user followers: %w", err)
+		return nil, err
+	}
+
+	user, err := s.repo.FindByID(refresh.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
 	}
 
-	var responses []*UserResponse
-	for _, u := range followers {
-		responses = append(responses, ToUserResponse(u))
+	accessToken, accessExpiresAt, err := s.generateAccessToken(user, refresh.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	return responses, nil
+	return &AuthTokens{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     raw,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
+}
+
+var (
+	ErrUserNotFound   = errors.New("user not found")
+	ErrPrivateAccount = errors.New("this account is private")
+
+	ErrInvalidBirthday     = errors.New("birthday must be a past date in YYYY-MM-DD format")
+	ErrInvalidProfileLink  = errors.New("links must be a JSON array of {label, url} with http or https URLs")
+	ErrTooManyProfileLinks = fmt.Errorf("a profile can have at most %d links", MaxProfileLinks)
+
+	ErrUsernameReserved = errors.New("username was recently used by another account and is reserved")
+)
+
+// checkUsernameAvailable menolak username yang sedang dipakai atau masih dicadangkan
+// untuk pemilik lamanya. userID adalah user yang akan memakai username (0 untuk user baru).
+func (s *service) checkUsernameAvailable(username string, userID uint) error {
+	existing, err := s.repo.FindByUsername(username)
+	if err == nil && existing != nil && existing.ID != userID {
+		return fmt.Errorf("username already in use")
+	}
+	reserved, err := s.repo.IsUsernameReserved(username, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check username: %w", err)
+	}
+	if reserved {
+		return ErrUsernameReserved
+	}
+	return nil
+}
+
+// resolveUsername mencari user berdasarkan username, atau username lama jika
+// tidak ada akun yang memakai username tersebut saat ini
+func (s *service) resolveUsername(username string) (*User, error) {
+	if u, err := s.repo.FindByUsername(username); err == nil {
+		return u, nil
+	}
+	history, err := s.repo.FindUsernameHistory(username)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	u, err := s.repo.FindByID(history.UserID)
+	if err != nil || u.PurgedAt != nil {
+		return nil, ErrUserNotFound
+	}
+	return u, nil
+}
+
+// canViewContent mengecek apakah viewer boleh melihat post dan daftar follow milik target
+func (s *service) canViewContent(target *User, viewerID uint) (bool, error) {
+	if !target.IsPrivate || target.ID == viewerID {
+		return true, nil
+	}
+	return s.repo.IsFollowing(viewerID, target.ID)
+}
+
+// GetUserFollowers implements Service.
+func (s *service) GetUserFollowers(currentUserID uint, page pagination.Page) ([]*UserResponse, string, error) {
+	followers, err := s.repo.FindFollowerByUsers(currentUserID, currentUserID, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get user followers: %w", err)
+	}
+
+	followers, next := pagination.Trim(followers, page, followCursor)
+	return toUserResponses(followers), next, nil
 }
 
 // GetUserFollowings implements Service.
-func (s *service) GetUserFollowings(currentUserID uint, limit int, offset int) ([]*UserResponse, error) {
-	followings, err := s.repo.FindFollowingUsers(currentUserID, limit, offset)
+func (s *service) GetUserFollowings(currentUserID uint, page pagination.Page) ([]*UserResponse, string, error) {
+	followings, err := s.repo.FindFollowingUsers(currentUserID, currentUserID, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get user followings: %w", err)
+	}
+
+	followings, next := pagination.Trim(followings, page, followCursor)
+	return toUserResponses(followings), next, nil
+}
+
+// GetFollowersByUsername implements Service.
+// Daftar follower akun private hanya bisa dilihat oleh follower yang sudah disetujui.
+func (s *service) GetFollowersByUsername(username string, currentUserID uint, page pagination.Page) ([]*UserResponse, string, error) {
+	target, err := s.resolveUsername(username)
+	if err != nil {
+		return nil, "", err
+	}
+	allowed, err := s.canViewContent(target, currentUserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to check follow status: %w", err)
+	}
+	if !allowed {
+		return nil, "", ErrPrivateAccount
+	}
+
+	followers, err := s.repo.FindFollowerByUsers(target.ID, currentUserID, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user followings: %w", err)
+		return nil, "", fmt.Errorf("failed to get user followers: %w", err)
 	}
+	followers, next := pagination.Trim(followers, page, followCursor)
+	return toUserResponses(followers), next, nil
+}
 
-	var responses []*UserResponse
-	for _, u := range followings {
-		responses = append(responses, ToUserResponse(u))
+// GetFollowingsByUsername implements Service.
+func (s *service) GetFollowingsByUsername(username string, currentUserID uint, page pagination.Page) ([]*UserResponse, string, error) {
+	target, err := s.resolveUsername(username)
+	if err != nil {
+		return nil, "", err
+	}
+	allowed, err := s.canViewContent(target, currentUserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to check follow status: %w", err)
+	}
+	if !allowed {
+		return nil, "", ErrPrivateAccount
 	}
 
-	return responses, nil
+	followings, err := s.repo.FindFollowingUsers(target.ID, currentUserID, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get user followings: %w", err)
+	}
+	followings, next := pagination.Trim(followings, page, followCursor)
+	return toUserResponses(followings), next, nil
 }
 
 // SearchByUsername implements Service.
-func (s *service) SearchUser(keyword string, currentUserID uint) ([]*UserResponse, error) {
+func (s *service) SearchUser(keyword string, currentUserID uint, page pagination.Page) ([]*UserResponse, string, error) {
 	// 1. validasi keyword
 	keyword = strings.TrimSpace(keyword)
 	if len(keyword) < 1 {
-		return []*UserResponse{}, nil
+		return []*UserResponse{}, "", nil
 	}
 
-	// 2. ambil data dari repo
-	users, err := s.repo.SearchByUsername(keyword, currentUserID, 10)
+	// 2. ambil data dari repo, user sendiri sudah dikecualikan di query
+	users, err := s.repo.SearchByUsername(keyword, currentUserID, page)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	users, next := pagination.Trim(users, page, func(u *User) pagination.Cursor {
+		return pagination.Cursor{ID: u.ID}
+	})
 
 	// 3. mapping ke response
-	var responses []*UserResponse
-	for _, u := range users {
-		// opsional: skip diri sendiri
-		if u.ID == currentUserID {
-			continue
-		}
-
-		responses = append(responses, ToUserResponse(u))
-	}
-
-	return responses, nil
+	return toUserResponses(users), next, nil
 }
 
 // GetUserDetailByID implements Service.
+// Username lama diarahkan ke akun pemiliknya dengan redirected_from terisi.
 func (s *service) GetUserDetailByUsername(username string, currentUserID uint) (*UserResponse, error) {
 	user, err := s.repo.FindUserDetailByUsername(username, currentUserID)
+	if err == nil {
+		return ToUserResponse(user), nil
+	}
+
+	owner, resolveErr := s.resolveUsername(username)
+	if resolveErr != nil || owner.Username == username {
+		return nil, fmt.Errorf("failed to get user detail by ID: %w", err)
+	}
+	user, err = s.repo.FindUserDetailByUsername(owner.Username, currentUserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user detail by ID: %w", err)
 	}
-	return ToUserResponse(user), nil
+	resp := ToUserResponse(user)
+	resp.RedirectedFrom = username
+	return resp, nil
 }
 
 // GetExploreUsers implements Service.
-func (s *service) GetExploreUsers(currentUserID uint, limit int, offset int) ([]UserResponse, error) {
-	users, err := s.repo.FindExploreUsers(currentUserID, limit, offset)
+func (s *service) GetExploreUsers(currentUserID uint, page pagination.Page) ([]UserResponse, string, error) {
+	users, err := s.repo.FindExploreUsers(currentUserID, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get explore users: %w", err)
+		return nil, "", fmt.Errorf("failed to get explore users: %w", err)
 	}
-	var responses []UserResponse
+	users, next := pagination.Trim(users, page, func(u User) pagination.Cursor {
+		return pagination.Cursor{ID: u.ID}
+	})
+	responses := make([]UserResponse, 0, len(users))
 	for _, u := range users {
 		responses = append(responses, *ToUserResponse(&u))
 	}
-	return responses, nil
+	return responses, next, nil
 }
 
 // GetUserByUsername implements Service.
@@ -141,26 +553,74 @@ func (s *service) GetUserByUsername(username string) (*UserResponse, error) {
 }
 
 type Claims struct {
-	ID   uint     `json:"id"`
-	Role RoleType `json:"role"`
+	ID        uint     `json:"id"`
+	Role      RoleType `json:"role"`
+	SessionID uint     `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func (s *service) GenerateToken(user *User) (string, error) {
+	signed, _, err := s.generateAccessToken(user, 0)
+	return signed, err
+}
+
+// generateAccessToken membuat access token berumur pendek untuk sebuah session.
+// Setiap token punya jti unik agar bisa dicabut saat logout.
+func (s *service) generateAccessToken(user *User, sessionID uint) (string, time.Time, error) {
 	duration, err := time.ParseDuration(s.cfg.JWTExpires)
 	if err != nil {
-		duration = 168 * time.Hour // default 7 days
+		duration = 15 * time.Minute // default 15 minutes
 	}
+	jti, err := token.GenerateRandomString(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	now := time.Now()
+	expiresAt := now.Add(duration)
 	claims := Claims{
-		ID:   user.ID,
-		Role: user.Role,
+		ID:        user.ID,
+		Role:      user.Role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.cfg.JWTSecret))
+	signed, err := jwtkeys.Get().Sign(claims, jwtkeys.TypeAccessToken)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// issueTokens membuat session baru beserta access token dan refresh token-nya
+func (s *service) issueTokens(user *User, client ClientInfo) (*AuthTokens, error) {
+	// login berhasil membatalkan penghapusan akun yang masih dalam masa tenggang
+	if user.DeletionScheduledAt != nil {
+		if err := s.repo.UpdateDeletionScheduledAt(user.ID, nil); err != nil {
+			return nil, fmt.Errorf("failed to cancel account deletion: %w", err)
+		}
+		user.DeletionScheduledAt = nil
+	}
+	session, err := s.tokenService.CreateSession(user.ID, client.UserAgent, client.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, accessExpiresAt, err := s.generateAccessToken(user, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	refreshToken, refresh, err := s.tokenService.IssueRefreshToken(user.ID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthTokens{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
 }
 
 // GetUserByID implements Service.
@@ -172,21 +632,457 @@ func (s *service) GetUserByID(userID uint) (*UserResponse, error) {
 	return ToUserResponse(user), nil
 }
 
+// ErrInvalidCredentials sengaja sama untuk email tidak terdaftar dan password salah
+// agar endpoint login tidak bisa dipakai untuk menebak email terdaftar.
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+var (
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+// compareDummyPassword menyamakan waktu respon saat email tidak ditemukan
+func compareDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
+
+func accountThrottleKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+func durationOrDefault(value string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+	return duration
+}
+
+func intOrDefault(value string, fallback int) int {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// checkLoginThrottle menolak login jika akun atau IP sedang dikunci
+func (s *service) checkLoginThrottle(keys ...string) error {
+	throttles, err := s.repo.FindLoginThrottles(keys)
+	if err != nil {
+		// jangan blokir login hanya karena tabel throttle bermasalah
+		log.Printf("Warning: failed to check login throttle: %v", err)
+		return nil
+	}
+	var retryAfter time.Duration
+	for _, throttle := range throttles {
+		if throttle.LockedUntil != nil {
+			if wait := time.Until(*throttle.LockedUntil); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure menambah counter gagal login dan mengunci key jika batas terlampaui.
+// Durasi lockout berlipat dua untuk setiap kegagalan setelah batas (exponential backoff).
+func (s *service) recordLoginFailure(key string, maxAttempts int) {
+	window := durationOrDefault(s.cfg.LoginAttemptWindow, 15*time.Minute)
+	throttle, err := s.repo.RecordLoginFailure(key, window)
+	if err != nil {
+		log.Printf("Warning: failed to record login failure: %v", err)
+		return
+	}
+	if throttle.FailedCount < maxAttempts {
+		return
+	}
+
+	base := durationOrDefault(s.cfg.LoginLockoutBase, time.Minute)
+	maxLockout := durationOrDefault(s.cfg.LoginLockoutMax, time.Hour)
+	lockout := base
+	for i := maxAttempts; i < throttle.FailedCount && lockout < maxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > maxLockout {
+		lockout = maxLockout
+	}
+	if err := s.repo.LockLoginThrottle(key, time.Now().Add(lockout)); err != nil {
+		log.Printf("Warning: failed to lock login throttle: %v", err)
+	}
+}
+
+func (s *service) recordFailedLogin(email, ip string) {
+	s.recordLoginFailure(accountThrottleKey(email), intOrDefault(s.cfg.LoginMaxAttempts, 5))
+	if ip != "" {
+		s.recordLoginFailure(ipThrottleKey(ip), intOrDefault(s.cfg.LoginMaxAttemptsPerIP, 20))
+	}
+}
+
 // Login implements Service.
-func (s *service) Login(req *LoginRequest) (string, *UserResponse, error) {
+func (s *service) Login(req *LoginRequest, client ClientInfo) (*LoginResult, error) {
+	if err := s.checkLoginThrottle(accountThrottleKey(req.Email), ipThrottleKey(client.IPAddress)); err != nil {
+		return nil, err
+	}
+
 	user, err := s.repo.FindByEmail(req.Email)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to get user by email: %w", err)
+		compareDummyPassword(req.Password)
+		s.recordFailedLogin(req.Email, client.IPAddress)
+		return nil, ErrInvalidCredentials
 	}
 	// Check password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return "", nil, fmt.Errorf("invalid password: %w", err)
+		s.recordFailedLogin(req.Email, client.IPAddress)
+		return nil, ErrInvalidCredentials
+	}
+
+	// counter per IP sengaja tidak di-reset agar satu akun valid tidak bisa dipakai
+	// untuk menghapus jejak password spraying dari IP yang sama
+	if err := s.repo.DeleteLoginThrottle(accountThrottleKey(user.Email)); err != nil {
+		log.Printf("Warning: failed to reset login throttle: %v", err)
 	}
-	token, err := s.GenerateToken(user)
+	return s.completeLogin(user, client)
+}
+
+// AssignRole implements Service.
+// Role dengan permission (admin, moderator) ikut mengikuti kewajiban 2FA admin.
+func (s *service) AssignRole(actorID, userID uint, role RoleType) (*UserResponse, error) {
+	if !rbac.IsValidRole(role) {
+		return nil, fmt.Errorf("invalid role, must be one of: %s", strings.Join(rbac.Roles(), ", "))
+	}
+	// mencegah admin terakhir tidak sengaja mengunci dirinya sendiri
+	if actorID == userID && role != RoleAdmin {
+		return nil, fmt.Errorf("cannot change your own role")
+	}
+
+	user, err := s.repo.FindByID(userID)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, fmt.Errorf("user not found")
 	}
-	return token, ToUserResponse(user), nil
+
+	twoFactorRequired := rbac.IsPrivileged(role) && s.cfg.AdminRequire2FA == "true"
+	if err := s.repo.UpdateRole(user.ID, role, twoFactorRequired); err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+	user.Role = role
+	user.TwoFactorRequired = twoFactorRequired
+	return ToAdminUserResponse(user), nil
+}
+
+// SuspendUser implements Service.
+func (s *service) SuspendUser(actorID uint, actorRole string, userID uint, req *SuspendUserRequest) (*SuspensionResponse, error) {
+	endsAt := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+	return s.suspend(actorID, actorRole, userID, SuspensionTypeSuspend, req.Reason, &endsAt)
+}
+
+// BanUser implements Service.
+func (s *service) BanUser(actorID uint, actorRole string, userID uint, req *BanUserRequest) (*SuspensionResponse, error) {
+	if !rbac.HasPermission(actorRole, rbac.PermUserBan) {
+		return nil, fmt.Errorf("not allowed to ban users")
+	}
+	return s.suspend(actorID, actorRole, userID, SuspensionTypeBan, req.Reason, nil)
+}
+
+func (s *service) suspend(actorID uint, actorRole string, userID uint, suspensionType, reason string, endsAt *time.Time) (*SuspensionResponse, error) {
+	if actorID == userID {
+		return nil, fmt.Errorf("cannot suspend your own account")
+	}
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err := checkCanModerate(actorRole, user); err != nil {
+		return nil, err
+	}
+
+	suspension := &UserSuspension{
+		UserID:   user.ID,
+		ActorID:  actorID,
+		Type:     suspensionType,
+		Reason:   strings.TrimSpace(reason),
+		StartsAt: time.Now(),
+		EndsAt:   endsAt,
+	}
+	if err := s.repo.CreateSuspension(suspension); err != nil {
+		return nil, fmt.Errorf("failed to suspend user: %w", err)
+	}
+	// keluarkan user dari semua perangkat
+	if err := s.LogoutAll(user.ID); err != nil {
+		log.Printf("Warning: failed to revoke sessions of suspended user: %v", err)
+	}
+
+	suspension.User = *user
+	return ToSuspensionResponse(suspension), nil
+}
+
+// checkCanModerate memastikan actor boleh menindak akun target:
+// moderator tidak boleh menindak sesama moderator atau admin
+func checkCanModerate(actorRole string, target *User) error {
+	if rbac.IsPrivileged(target.Role) && !rbac.HasPermission(actorRole, rbac.PermUserAssignRole) {
+		return fmt.Errorf("cannot suspend a privileged account")
+	}
+	return nil
+}
+
+// LiftSuspension implements Service.
+// Pemeriksaan actor sama dengan saat menindak, dan ban hanya bisa dicabut oleh
+// role yang boleh mem-ban.
+func (s *service) LiftSuspension(actorID uint, actorRole string, userID uint) error {
+	if actorID == userID {
+		return fmt.Errorf("cannot lift your own suspension")
+	}
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if err := checkCanModerate(actorRole, user); err != nil {
+		return err
+	}
+	if user.BannedAt != nil && !rbac.HasPermission(actorRole, rbac.PermUserBan) {
+		return fmt.Errorf("not allowed to lift a ban")
+	}
+
+	lifted, err := s.repo.LiftSuspension(userID, actorID)
+	if err != nil {
+		return fmt.Errorf("failed to lift suspension: %w", err)
+	}
+	if !lifted {
+		return fmt.Errorf("user is not suspended")
+	}
+	return nil
+}
+
+// GetSuspensions implements Service.
+func (s *service) GetSuspensions(activeOnly bool, page pagination.Page) ([]*SuspensionResponse, string, error) {
+	suspensions, err := s.repo.FindSuspensions(activeOnly, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get suspensions: %w", err)
+	}
+	suspensions, next := pagination.Trim(suspensions, page, func(us *UserSuspension) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: us.CreatedAt, ID: us.ID}
+	})
+	responses := []*SuspensionResponse{}
+	for _, suspension := range suspensions {
+		responses = append(responses, ToSuspensionResponse(suspension))
+	}
+	return responses, next, nil
+}
+
+// UnlockAccount implements Service.
+func (s *service) UnlockAccount(userID uint) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if err := s.repo.DeleteLoginThrottle(accountThrottleKey(user.Email)); err != nil {
+		return fmt.Errorf("failed to unlock account: %w", err)
+	}
+	return nil
+}
+
+// completeLogin menerbitkan token untuk user yang sudah terautentikasi
+// (password maupun social login), atau challenge token jika 2FA aktif.
+func (s *service) completeLogin(user *User, client ClientInfo) (*LoginResult, error) {
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+	// 2FA aktif: token asli baru diterbitkan setelah kode TOTP diverifikasi
+	if user.TwoFactorEnabled {
+		challenge, err := s.signPurposeToken(user, purposeTwoFactorLogin, twoFactorChallengeTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate challenge token: %w", err)
+		}
+		return &LoginResult{
+			ChallengeToken:     challenge,
+			ChallengeExpiresAt: time.Now().Add(twoFactorChallengeTTL),
+		}, nil
+	}
+
+	tokens, err := s.issueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: tokens, User: ToOwnUserResponse(user)}, nil
+}
+
+// VerifyTwoFactorLogin implements Service.
+// Menukar challenge token + kode TOTP (atau recovery code) dengan access/refresh token.
+func (s *service) VerifyTwoFactorLogin(req *TwoFactorLoginRequest, client ClientInfo) (*AuthTokens, *UserResponse, error) {
+	userID, _, err := s.parsePurposeToken(req.ChallengeToken, purposeTwoFactorLogin)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.repo.FindByID(userID)
+	if err != nil || !user.TwoFactorEnabled {
+		return nil, nil, fmt.Errorf("invalid or expired token")
+	}
+	if err := checkAccountStatus(user); err != nil {
+		return nil, nil, err
+	}
+	// kode TOTP hanya 6 digit, jadi ikut dibatasi oleh lockout akun dan IP
+	if err := s.checkLoginThrottle(accountThrottleKey(user.Email), ipThrottleKey(client.IPAddress)); err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case req.Code != "":
+		if err := s.verifyTOTP(user, req.Code); err != nil {
+			s.recordFailedLogin(user.Email, client.IPAddress)
+			return nil, nil, err
+		}
+	case req.RecoveryCode != "":
+		used, err := s.repo.UseRecoveryCode(user.ID, token.HashToken(normalizeRecoveryCode(req.RecoveryCode)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to verify recovery code: %w", err)
+		}
+		if !used {
+			s.recordFailedLogin(user.Email, client.IPAddress)
+			return nil, nil, fmt.Errorf("invalid recovery code")
+		}
+	default:
+		return nil, nil, fmt.Errorf("code or recovery_code is required")
+	}
+
+	tokens, err := s.issueTokens(user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, ToOwnUserResponse(user), nil
+}
+
+// EnrollTwoFactor implements Service.
+// Secret baru disimpan tapi 2FA belum aktif sampai dikonfirmasi dengan kode yang valid.
+func (s *service) EnrollTwoFactor(userID uint) (*TwoFactorEnrollResponse, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if user.TwoFactorEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if err := s.repo.Update(user, "totp_secret", "totp_last_step"); err != nil {
+		return nil, fmt.Errorf("failed to save secret: %w", err)
+	}
+
+	return &TwoFactorEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.cfg.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor implements Service.
+func (s *service) ConfirmTwoFactor(userID uint, code string) (*RecoveryCodesResponse, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if user.TwoFactorEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, fmt.Errorf("two-factor enrollment has not been started")
+	}
+	if err := s.verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+	if err := s.repo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+
+	user.TwoFactorEnabled = true
+	if err := s.repo.Update(user, "two_factor_enabled"); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor implements Service.
+func (s *service) DisableTwoFactor(userID uint, req *TwoFactorDisableRequest) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if !user.TwoFactorEnabled {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+	if user.TwoFactorRequired {
+		return fmt.Errorf("two-factor authentication is required for this account")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return fmt.Errorf("invalid password")
+	}
+	if err := s.verifyTOTP(user, req.Code); err != nil {
+		return err
+	}
+
+	user.TwoFactorEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	if err := s.repo.Update(user, "two_factor_enabled", "totp_secret", "totp_last_step"); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	return s.repo.DeleteRecoveryCodes(user.ID)
+}
+
+// verifyTOTP memvalidasi kode dan memastikan kode yang sama tidak bisa dipakai dua kali
+func (s *service) verifyTOTP(user *User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), 1)
+	if !ok {
+		return fmt.Errorf("invalid two-factor code")
+	}
+	consumed, err := s.repo.ConsumeTOTPStep(user.ID, step)
+	if err != nil {
+		return fmt.Errorf("failed to verify two-factor code: %w", err)
+	}
+	if !consumed {
+		return fmt.Errorf("two-factor code has already been used")
+	}
+	return nil
+}
+
+// generateRecoveryCodes membuat recovery code (format xxxx-xxxx) beserta hash-nya
+func generateRecoveryCodes(n int) ([]string, []string, error) {
+	encoder := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, n)
+	hashes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoder.EncodeToString(b))
+		codes = append(codes, raw[:4]+"-"+raw[4:])
+		hashes = append(hashes, token.HashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
 }
 
 // Register implements Service.
@@ -195,9 +1091,8 @@ func (s *service) Register(req *RegisterRequest) (*UserResponse, error) {
 	if existingEmail != nil {
 		return nil, fmt.Errorf("email already in use")
 	}
-	existingUsername, _ := s.repo.FindByUsername(req.Username)
-	if existingUsername != nil {
-		return nil, fmt.Errorf("username already in use")
+	if err := s.checkUsernameAvailable(req.Username, 0); err != nil {
+		return nil, err
 	}
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -212,7 +1107,17 @@ func (s *service) Register(req *RegisterRequest) (*UserResponse, error) {
 	if err := s.repo.Create(u); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
-	return ToUserResponse(u), nil
+	// Akun dibuat dalam keadaan belum terverifikasi; gagal kirim email tidak membatalkan registrasi
+	if err := s.sendVerificationEmail(u); err != nil {
+		log.Printf("Warning: failed to send verification email: %v", err)
+	}
+	return ToOwnUserResponse(u), nil
+}
+
+// profileColumns adalah kolom yang boleh diubah lewat UpdateProfile
+var profileColumns = []string{
+	"avatar", "bio", "banner", "display_name", "location", "links",
+	"birthday", "birthday_visibility", "is_private",
 }
 
 // UpdateProfile implements Service.
@@ -222,6 +1127,8 @@ func (s *service) UpdateProfile(userID uint, req *UpdateProfileRequest) (*UserRe
 		return nil, fmt.Errorf("user not found")
 	}
 
+	oldUsername := user.Username
+
 	// update allowed fields
 	if req.Username != nil {
 		user.Username = *req.Username
@@ -233,10 +1140,51 @@ func (s *service) UpdateProfile(userID uint, req *UpdateProfileRequest) (*UserRe
 	if req.Bio != nil {
 		user.Bio = *req.Bio
 	}
+	if req.Banner != nil {
+		user.Banner = *req.Banner
+	}
+	if req.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Location != nil {
+		user.Location = strings.TrimSpace(*req.Location)
+	}
+	if req.Links != nil {
+		links, err := parseProfileLinks(*req.Links)
+		if err != nil {
+			return nil, err
+		}
+		user.Links = links
+	}
+	if req.Birthday != nil {
+		birthday, err := parseBirthday(*req.Birthday)
+		if err != nil {
+			return nil, err
+		}
+		user.Birthday = birthday
+	}
+	if req.BirthdayVisibility != nil {
+		user.BirthdayVisibility = *req.BirthdayVisibility
+	}
+	becamePublic := false
+	if req.IsPrivate != nil {
+		becamePublic = user.IsPrivate && !*req.IsPrivate
+		user.IsPrivate = *req.IsPrivate
+	}
 
-	existingUsername, err := s.repo.FindByUsername(user.Username)
-	if err == nil && existingUsername != nil && existingUsername.ID != user.ID {
-		return nil, fmt.Errorf("username already in use")
+	// username lama disimpan di riwayat dan dicadangkan agar link lama tetap
+	// mengarah ke akun ini; penggantian berikutnya dibatasi cooldown
+	usernameChanged := user.Username != oldUsername
+	if usernameChanged {
+		if user.UsernameChangedAt != nil {
+			cooldown := durationOrDefault(s.cfg.UsernameChangeCooldown, 14*24*time.Hour)
+			if retry := time.Until(user.UsernameChangedAt.Add(cooldown)); retry > 0 {
+				return nil, &UsernameChangeTooSoonError{RetryAfter: retry}
+			}
+		}
+		if err := s.checkUsernameAvailable(user.Username, user.ID); err != nil {
+			return nil, err
+		}
 	}
 
 	existingEmail, err := s.repo.FindByEmail(user.Email)
@@ -244,13 +1192,239 @@ func (s *service) UpdateProfile(userID uint, req *UpdateProfileRequest) (*UserRe
 		return nil, fmt.Errorf("email already in use")
 	}
 
-	if err := s.repo.Update(user); err != nil {
+	if usernameChanged {
+		now := time.Now()
+		user.UsernameChangedAt = &now
+		history := &UsernameHistory{
+			UserID:        user.ID,
+			Username:      oldUsername,
+			ReservedUntil: now.Add(durationOrDefault(s.cfg.UsernameReservationPeriod, 90*24*time.Hour)),
+		}
+		if err := s.repo.ChangeUsername(user, history, profileColumns...); err != nil {
+			return nil, fmt.Errorf("failed to update user profile: %w", err)
+		}
+	} else if err := s.repo.Update(user, profileColumns...); err != nil {
 		return nil, fmt.Errorf("failed to update user profile: %w", err)
 	}
+	if becamePublic {
+		if err := s.repo.AcceptPendingFollowRequests(user.ID); err != nil {
+			log.Printf("Warning: failed to accept pending follow requests: %v", err)
+		}
+	}
 
-	return ToUserResponse(user), nil
+	return ToOwnUserResponse(user), nil
 }
 
-func NewService(repo Repository, cfg *config.Config) Service {
-	return &service{repo: repo, cfg: cfg}
+// oidcStateClaims disimpan di cookie selama redirect ke provider. Cookie ini
+// ditandatangani sehingga state, nonce dan PKCE verifier tidak bisa diubah client.
+type oidcStateClaims struct {
+	Purpose  string `json:"purpose"`
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+// StartOIDCLogin implements Service.
+// Mengembalikan URL authorization provider dan state token yang harus disimpan di cookie.
+func (s *service) StartOIDCLogin(ctx context.Context, providerName string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", oidc.ErrProviderNotFound
+	}
+
+	var random [3]string
+	for i, size := range []int{24, 24, 48} {
+		value, err := token.GenerateRandomString(size)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to generate state token: %w", err)
+		}
+		random[i] = value
+	}
+	claims := oidcStateClaims{
+		Purpose:  purposeOIDCState,
+		Provider: providerName,
+		State:    random[0],
+		Nonce:    random[1],
+		Verifier: random[2],
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(oidcStateTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	stateToken, err := jwtkeys.Get().Sign(claims, "")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate state token: %w", err)
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, claims.State, claims.Nonce, claims.Verifier)
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, stateToken, nil
+}
+
+// CompleteOIDCLogin implements Service.
+// Menukar authorization code, lalu login ke akun yang sudah terhubung, menghubungkan
+// ke akun dengan email terverifikasi yang sama, atau membuat akun baru.
+func (s *service) CompleteOIDCLogin(ctx context.Context, providerName string, req *OIDCCallbackRequest, stateToken string, client ClientInfo) (*LoginResult, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, oidc.ErrProviderNotFound
+	}
+	if req.Error != "" {
+		return nil, fmt.Errorf("provider returned error: %s", req.Error)
+	}
+
+	state := &oidcStateClaims{}
+	keyring := jwtkeys.Get()
+	parsed, err := jwt.ParseWithClaims(stateToken, state, keyring.Keyfunc, jwt.WithValidMethods(keyring.ValidMethods()))
+	if err != nil || !parsed.Valid || state.Purpose != purposeOIDCState || state.Provider != providerName {
+		return nil, fmt.Errorf("invalid or expired login state")
+	}
+	if req.State == "" || req.State != state.State || req.Code == "" {
+		return nil, fmt.Errorf("invalid or expired login state")
+	}
+
+	claims, err := provider.Exchange(ctx, req.Code, state.Verifier, state.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("provider did not return a subject")
+	}
+
+	user, err := s.findOrCreateOIDCUser(providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+	return s.completeLogin(user, client)
+}
+
+func (s *service) findOrCreateOIDCUser(providerName string, claims *oidc.Claims) (*User, error) {
+	// sudah pernah login dengan provider ini
+	if identity, err := s.repo.FindIdentity(providerName, claims.Subject); err == nil {
+		user, err := s.repo.FindByID(identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user by ID: %w", err)
+		}
+		return user, nil
+	}
+
+	if claims.Email == "" {
+		return nil, fmt.Errorf("provider did not return an email address")
+	}
+	identity := &UserIdentity{
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+
+	// Hubungkan ke akun yang sudah ada hanya jika provider menjamin email-nya terverifikasi,
+	// jika tidak siapa pun bisa mengambil alih akun dengan mendaftarkan email orang lain.
+	if existing, err := s.repo.FindByEmail(claims.Email); err == nil && existing != nil {
+		if !claims.EmailVerified {
+			return nil, fmt.Errorf("email already in use, sign in with your password first")
+		}
+		identity.UserID = existing.ID
+		if err := s.repo.CreateIdentity(identity); err != nil {
+			return nil, fmt.Errorf("failed to link account: %w", err)
+		}
+		if existing.EmailVerifiedAt == nil {
+			now := time.Now()
+			existing.EmailVerifiedAt = &now
+			if err := s.repo.Update(existing, "email_verified_at"); err != nil {
+				return nil, fmt.Errorf("failed to update user: %w", err)
+			}
+		}
+		return existing, nil
+	}
+
+	username, err := s.generateUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+	// akun social login tetap punya password acak agar kolom password tidak kosong;
+	// user bisa menggantinya lewat forgot password
+	randomPassword, err := token.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	u := &User{
+		Username: username,
+		Email:    claims.Email,
+		Password: string(hashedPassword),
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		u.EmailVerifiedAt = &now
+	}
+	if err := s.repo.CreateWithIdentity(u, identity); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	if u.EmailVerifiedAt == nil {
+		if err := s.sendVerificationEmail(u); err != nil {
+			log.Printf("Warning: failed to send verification email: %v", err)
+		}
+	}
+	return u, nil
+}
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+const (
+	usernameMinLength = 3
+	usernameMaxLength = 20
+)
+
+// generateUsername membuat username unik dari preferred_username, nama, atau email
+func (s *service) generateUsername(claims *oidc.Claims) (string, error) {
+	base := ""
+	for _, candidate := range []string{claims.PreferredUsername, strings.Split(claims.Email, "@")[0], claims.Name} {
+		base = strings.Trim(usernameInvalidChars.ReplaceAllString(strings.ToLower(candidate), "_"), "_")
+		if len(base) >= usernameMinLength {
+			break
+		}
+	}
+	if len(base) < usernameMinLength {
+		base = "user"
+	}
+	if len(base) > usernameMaxLength {
+		base = base[:usernameMaxLength]
+	}
+
+	username := base
+	for i := 0; i < 10; i++ {
+		if s.checkUsernameAvailable(username, 0) == nil {
+			return username, nil
+		}
+		suffix := strconv.Itoa(1000 + int(randomUint32()%9000))
+		if len(base)+1+len(suffix) > usernameMaxLength {
+			username = base[:usernameMaxLength-1-len(suffix)] + "_" + suffix
+		} else {
+			username = base + "_" + suffix
+		}
+	}
+	return "", fmt.Errorf("failed to generate unique username")
+}
+
+func randomUint32() uint32 {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
+
+func NewService(repo Repository, tokenService token.Service, mailer mailer.Mailer, cfg *config.Config) Service {
+	return &service{
+		repo:         repo,
+		tokenService: tokenService,
+		mailer:       mailer,
+		providers:    oidc.NewProviders(cfg.OIDCProviders),
+		cfg:          cfg,
+	}
 }
@@ -0,0 +1,279 @@
+package verification
+
+import (
+	"errors"
+	"go-sosmed/pkg/pagination"
+	"go-sosmed/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service Service
+}
+
+// helper function to get userID from context
+func GetUserIDFromContext(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	uid, ok := userID.(uint)
+	return uid, ok
+}
+
+func ParseRequestID(c *gin.Context) (uint, error) {
+	idParam := c.Param("request_id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+func ParseUserID(c *gin.Context) (uint, error) {
+	idParam := c.Param("user_id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// writeReviewError memetakan error review ke status HTTP
+func writeReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrRequestNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrAlreadyReviewed):
+		response.Error(c, http.StatusConflict, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// SubmitRequest godoc
+// @Summary Request account verification
+// @Description Submit a request for a verified badge with supporting text. Only one request can wait for review at a time, and a rejected user must wait before submitting again.
+// @Tags Verification
+// @Accept json
+// @Produce json
+// @Param request body SubmitVerificationRequest true "Supporting text"
+// @Security BearerAuth
+// @Success 201 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/verification [post]
+func (ctrl *Controller) SubmitRequest(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req SubmitVerificationRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	request, err := ctrl.service.SubmitRequest(userID, &req)
+	if err != nil {
+		var tooSoonErr *ResubmitTooSoonError
+		switch {
+		case errors.As(err, &tooSoonErr):
+			c.Header("Retry-After", strconv.Itoa(int(tooSoonErr.RetryAfter.Seconds())+1))
+			response.Error(c, http.StatusTooManyRequests, err.Error())
+		case errors.Is(err, ErrAlreadyVerified), errors.Is(err, ErrRequestPending):
+			response.Error(c, http.StatusConflict, err.Error())
+		case errors.Is(err, ErrUserNotFound):
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "verification request submitted successfully", request)
+}
+
+// GetMyRequest godoc
+// @Summary Get verification request status
+// @Description Get the latest verification request of the authenticated user
+// @Tags Verification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/users/me/verification [get]
+func (ctrl *Controller) GetMyRequest(c *gin.Context) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	request, err := ctrl.service.GetMyRequest(userID)
+	if err != nil {
+		if errors.Is(err, ErrRequestNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "verification request retrieved successfully", request)
+}
+
+// GetRequests godoc
+// @Summary List verification requests
+// @Description List verification requests by status, oldest first. Defaults to the pending review queue.
+// @Tags Admin
+// @Produce json
+// @Param status query string false "Status" Enums(pending, approved, rejected) default(pending)
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Security BearerAuth
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/admin/verifications [get]
+func (ctrl *Controller) GetRequests(c *gin.Context) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	requests, next, err := ctrl.service.GetRequests(c.DefaultQuery("status", StatusPending), page)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatus) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Paginated(c, http.StatusOK, "verification requests retrieved successfully", requests, next)
+}
+
+// ApproveRequest godoc
+// @Summary Approve verification request
+// @Description Approve a pending verification request and give the user a verified badge
+// @Tags Admin
+// @Produce json
+// @Param request_id path int true "Verification request ID"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/admin/verifications/{request_id}/approve [post]
+func (ctrl *Controller) ApproveRequest(c *gin.Context) {
+	reviewerID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	requestID, err := ParseRequestID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request ID")
+		return
+	}
+
+	request, err := ctrl.service.ApproveRequest(reviewerID, requestID)
+	if err != nil {
+		writeReviewError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "verification request approved", request)
+}
+
+// RejectRequest godoc
+// @Summary Reject verification request
+// @Description Reject a pending verification request. The note is sent to the user.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request_id path int true "Verification request ID"
+// @Param request body RejectVerificationRequest true "Rejection note"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/admin/verifications/{request_id}/reject [post]
+func (ctrl *Controller) RejectRequest(c *gin.Context) {
+	reviewerID, ok := GetUserIDFromContext(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	requestID, err := ParseRequestID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request ID")
+		return
+	}
+
+	var req RejectVerificationRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	request, err := ctrl.service.RejectRequest(reviewerID, requestID, &req)
+	if err != nil {
+		writeReviewError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "verification request rejected", request)
+}
+
+// RevokeVerification godoc
+// @Summary Revoke verified badge
+// @Description Remove the verified badge from a user
+// @Tags Admin
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/admin/users/{user_id}/verification [delete]
+func (ctrl *Controller) RevokeVerification(c *gin.Context) {
+	userID, err := ParseUserID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := ctrl.service.RevokeVerification(userID); err != nil {
+		if errors.Is(err, ErrNotVerified) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "verification revoked successfully", nil)
+}
+
+func NewController(service Service) *Controller {
+	return &Controller{service: service}
+}
@@ -0,0 +1,20 @@
+package verification
+
+import "go-sosmed/internal/user"
+
+func ToVerificationResponse(v *VerificationRequest) *VerificationResponse {
+	return &VerificationResponse{
+		ID: v.ID,
+		User: user.AuthorResponse{
+			ID:         v.User.ID,
+			Username:   v.User.Username,
+			Avatar:     v.User.Avatar,
+			IsVerified: v.User.VerifiedAt != nil,
+		},
+		Reason:     v.Reason,
+		Status:     v.Status,
+		ReviewNote: v.ReviewNote,
+		ReviewedAt: v.ReviewedAt,
+		CreatedAt:  v.CreatedAt,
+	}
+}
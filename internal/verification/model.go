@@ -0,0 +1,57 @@
+package verification
+
+import (
+	"fmt"
+	"go-sosmed/internal/user"
+	"time"
+)
+
+type StatusType = string
+
+const (
+	StatusPending  StatusType = "pending"
+	StatusApproved StatusType = "approved"
+	StatusRejected StatusType = "rejected"
+)
+
+// VerificationRequest adalah permintaan badge akun terverifikasi yang ditinjau admin
+type VerificationRequest struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"not null;index"`
+	Reason     string     `gorm:"type:text;not null"` // teks pendukung dari user
+	Status     StatusType `gorm:"size:20;default:'pending';index"`
+	ReviewerID *uint
+	ReviewNote string `gorm:"type:text"`
+	ReviewedAt *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+	//relations
+	User user.User `gorm:"foreignKey:UserID"`
+}
+
+type SubmitVerificationRequest struct {
+	Reason string `json:"reason" form:"reason" binding:"required,min=20,max=2000"`
+}
+
+type RejectVerificationRequest struct {
+	Note string `json:"note" form:"note" binding:"required,max=500"`
+}
+
+type VerificationResponse struct {
+	ID         uint                `json:"id"`
+	User       user.AuthorResponse `json:"user"`
+	Reason     string              `json:"reason"`
+	Status     string              `json:"status"`
+	ReviewNote string              `json:"review_note,omitempty"`
+	ReviewedAt *time.Time          `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// ResubmitTooSoonError dikembalikan jika user mengajukan lagi terlalu cepat setelah ditolak
+type ResubmitTooSoonError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ResubmitTooSoonError) Error() string {
+	return fmt.Sprintf("your previous verification request was rejected recently, try again in %s", e.RetryAfter.Round(time.Hour))
+}
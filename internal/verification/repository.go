@@ -0,0 +1,138 @@
+package verification
+
+import (
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/pagination"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(request *VerificationRequest) error
+	FindByID(id uint) (*VerificationRequest, error)
+	FindLatestByUser(userID uint) (*VerificationRequest, error)
+	FindByStatus(status StatusType, page pagination.Page) ([]*VerificationRequest, error)
+	Approve(id, reviewerID uint) (bool, error)
+	Reject(id, reviewerID uint, note string) (bool, error)
+	FindUser(userID uint) (*user.User, error)
+	RevokeUserVerification(userID uint) (bool, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// Create implements Repository.
+func (r *repository) Create(request *VerificationRequest) error {
+	return r.db.Create(request).Error
+}
+
+// FindByID implements Repository.
+func (r *repository) FindByID(id uint) (*VerificationRequest, error) {
+	var request VerificationRequest
+	if err := r.db.Preload("User").First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindLatestByUser implements Repository.
+func (r *repository) FindLatestByUser(userID uint) (*VerificationRequest, error) {
+	var request VerificationRequest
+	err := r.db.
+		Preload("User").
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindByStatus implements Repository.
+// Antrian diurutkan dari permintaan paling lama agar ditinjau lebih dulu.
+func (r *repository) FindByStatus(status StatusType, page pagination.Page) ([]*VerificationRequest, error) {
+	var requests []*VerificationRequest
+	err := r.db.
+		Preload("User").
+		Where("status = ?", status).
+		Scopes(page.Oldest("verification_requests.created_at", "verification_requests.id")).
+		Find(&requests).Error
+	return requests, err
+}
+
+// Approve implements Repository.
+// Status request dan badge user diubah dalam satu transaksi. Update bersyarat
+// mencegah request yang sama diproses dua kali oleh admin berbeda.
+func (r *repository) Approve(id, reviewerID uint) (bool, error) {
+	approved := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&VerificationRequest{}).
+			Where("id = ? AND status = ?", id, StatusPending).
+			Updates(map[string]interface{}{
+				"status":      StatusApproved,
+				"reviewer_id": reviewerID,
+				"reviewed_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var request VerificationRequest
+		if err := tx.Select("user_id").First(&request, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&user.User{}).Where("id = ?", request.UserID).Update("verified_at", now).Error; err != nil {
+			return err
+		}
+		approved = true
+		return nil
+	})
+	return approved, err
+}
+
+// Reject implements Repository.
+func (r *repository) Reject(id, reviewerID uint, note string) (bool, error) {
+	result := r.db.Model(&VerificationRequest{}).
+		Where("id = ? AND status = ?", id, StatusPending).
+		Updates(map[string]interface{}{
+			"status":      StatusRejected,
+			"reviewer_id": reviewerID,
+			"review_note": note,
+			"reviewed_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindUser implements Repository.
+func (r *repository) FindUser(userID uint) (*user.User, error) {
+	var u user.User
+	if err := r.db.Where("purged_at IS NULL").First(&u, userID).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// RevokeUserVerification implements Repository.
+func (r *repository) RevokeUserVerification(userID uint) (bool, error) {
+	result := r.db.Model(&user.User{}).
+		Where("id = ? AND verified_at IS NOT NULL", userID).
+		Update("verified_at", nil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,23 @@
+package verification
+
+import (
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/middlewares"
+	"go-sosmed/pkg/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRoute(r *gin.Engine, ctrl *Controller, cfg *config.Config) {
+	api := r.Group("/api/users/me/verification")
+	api.Use(middlewares.Authenticate(cfg))
+	api.GET("", middlewares.RequireScope(middlewares.ScopeUsersRead), ctrl.GetMyRequest)
+	api.POST("", middlewares.RequireScope(middlewares.ScopeProfileWrite), middlewares.RequireVerifiedEmail(), ctrl.SubmitRequest)
+
+	adminAPI := r.Group("/api/admin")
+	adminAPI.Use(middlewares.Authenticate(cfg), middlewares.RequireSessionAuth(), middlewares.RequireTwoFactor())
+	adminAPI.GET("/verifications", middlewares.RequirePermission(rbac.PermUserVerify), ctrl.GetRequests)
+	adminAPI.POST("/verifications/:request_id/approve", middlewares.RequirePermission(rbac.PermUserVerify), ctrl.ApproveRequest)
+	adminAPI.POST("/verifications/:request_id/reject", middlewares.RequirePermission(rbac.PermUserVerify), ctrl.RejectRequest)
+	adminAPI.DELETE("/users/:user_id/verification", middlewares.RequirePermission(rbac.PermUserVerify), ctrl.RevokeVerification)
+}
@@ -0,0 +1,198 @@
+package verification
+
+import (
+	"errors"
+	"fmt"
+	"go-sosmed/internal/user"
+	"go-sosmed/pkg/mailer"
+	"go-sosmed/pkg/pagination"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAlreadyVerified = errors.New("account is already verified")
+	ErrRequestPending  = errors.New("a verification request is already waiting for review")
+	ErrRequestNotFound = errors.New("verification request not found")
+	ErrAlreadyReviewed = errors.New("verification request has already been reviewed")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrNotVerified     = errors.New("account is not verified")
+	ErrInvalidStatus   = errors.New("invalid status")
+)
+
+// jeda sebelum user boleh mengajukan lagi setelah permintaannya ditolak
+const resubmitCooldown = 30 * 24 * time.Hour
+
+type Service interface {
+	SubmitRequest(userID uint, req *SubmitVerificationRequest) (*VerificationResponse, error)
+	GetMyRequest(userID uint) (*VerificationResponse, error)
+	GetRequests(status StatusType, page pagination.Page) ([]*VerificationResponse, string, error)
+	ApproveRequest(reviewerID, requestID uint) (*VerificationResponse, error)
+	RejectRequest(reviewerID, requestID uint, req *RejectVerificationRequest) (*VerificationResponse, error)
+	RevokeVerification(userID uint) error
+}
+
+type service struct {
+	repo   Repository
+	mailer mailer.Mailer
+}
+
+// SubmitRequest implements Service.
+func (s *service) SubmitRequest(userID uint, req *SubmitVerificationRequest) (*VerificationResponse, error) {
+	u, err := s.repo.FindUser(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if u.VerifiedAt != nil {
+		return nil, ErrAlreadyVerified
+	}
+
+	latest, err := s.repo.FindLatestByUser(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check previous request: %w", err)
+	}
+	if latest != nil {
+		switch latest.Status {
+		case StatusPending:
+			return nil, ErrRequestPending
+		case StatusRejected:
+			if latest.ReviewedAt != nil {
+				if retry := time.Until(latest.ReviewedAt.Add(resubmitCooldown)); retry > 0 {
+					return nil, &ResubmitTooSoonError{RetryAfter: retry}
+				}
+			}
+		}
+	}
+
+	request := &VerificationRequest{
+		UserID: userID,
+		Reason: req.Reason,
+		Status: StatusPending,
+		User:   *u,
+	}
+	if err := s.repo.Create(request); err != nil {
+		return nil, fmt.Errorf("failed to create verification request: %w", err)
+	}
+	return ToVerificationResponse(request), nil
+}
+
+// GetMyRequest implements Service.
+func (s *service) GetMyRequest(userID uint) (*VerificationResponse, error) {
+	request, err := s.repo.FindLatestByUser(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get verification request: %w", err)
+	}
+	return ToVerificationResponse(request), nil
+}
+
+// GetRequests implements Service.
+func (s *service) GetRequests(status StatusType, page pagination.Page) ([]*VerificationResponse, string, error) {
+	if status != StatusPending && status != StatusApproved && status != StatusRejected {
+		return nil, "", ErrInvalidStatus
+	}
+	requests, err := s.repo.FindByStatus(status, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get verification requests: %w", err)
+	}
+	requests, next := pagination.Trim(requests, page, func(r *VerificationRequest) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: r.CreatedAt, ID: r.ID}
+	})
+	responses := make([]*VerificationResponse, 0, len(requests))
+	for _, r := range requests {
+		responses = append(responses, ToVerificationResponse(r))
+	}
+	return responses, next, nil
+}
+
+// ApproveRequest implements Service.
+func (s *service) ApproveRequest(reviewerID, requestID uint) (*VerificationResponse, error) {
+	if _, err := s.findPending(requestID); err != nil {
+		return nil, err
+	}
+	approved, err := s.repo.Approve(requestID, reviewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve verification request: %w", err)
+	}
+	if !approved {
+		return nil, ErrAlreadyReviewed
+	}
+
+	request, err := s.repo.FindByID(requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification request: %w", err)
+	}
+	s.notify(&request.User, "Your account is now verified",
+		"Your verification request has been approved. A verified badge is now shown next to your name.")
+	return ToVerificationResponse(request), nil
+}
+
+// RejectRequest implements Service.
+func (s *service) RejectRequest(reviewerID, requestID uint, req *RejectVerificationRequest) (*VerificationResponse, error) {
+	if _, err := s.findPending(requestID); err != nil {
+		return nil, err
+	}
+	rejected, err := s.repo.Reject(requestID, reviewerID, req.Note)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject verification request: %w", err)
+	}
+	if !rejected {
+		return nil, ErrAlreadyReviewed
+	}
+
+	request, err := s.repo.FindByID(requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification request: %w", err)
+	}
+	s.notify(&request.User, "Your verification request was not approved", fmt.Sprintf(
+		"Your verification request was not approved.\n\nReason: %s\n\nYou can submit a new request after %s.",
+		req.Note, request.ReviewedAt.Add(resubmitCooldown).Format(time.RFC1123),
+	))
+	return ToVerificationResponse(request), nil
+}
+
+func (s *service) findPending(requestID uint) (*VerificationRequest, error) {
+	request, err := s.repo.FindByID(requestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get verification request: %w", err)
+	}
+	if request.Status != StatusPending {
+		return nil, ErrAlreadyReviewed
+	}
+	return request, nil
+}
+
+// RevokeVerification implements Service.
+// Menghapus badge tanpa mengubah riwayat request; user bisa mengajukan lagi.
+func (s *service) RevokeVerification(userID uint) error {
+	revoked, err := s.repo.RevokeUserVerification(userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke verification: %w", err)
+	}
+	if !revoked {
+		return ErrNotVerified
+	}
+	return nil
+}
+
+// notify mengirim hasil review ke email user; gagal kirim tidak membatalkan review
+func (s *service) notify(u *user.User, subject, body string) {
+	if err := s.mailer.Send(mailer.Message{
+		To:      u.Email,
+		Subject: subject,
+		Body:    fmt.Sprintf("Hi %s,\n\n%s\n", u.Username, body),
+	}); err != nil {
+		log.Printf("Warning: failed to send verification email: %v", err)
+	}
+}
+
+func NewService(repo Repository, mailer mailer.Mailer) Service {
+	return &service{repo: repo, mailer: mailer}
+}
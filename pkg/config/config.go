@@ -5,6 +5,7 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -18,12 +19,20 @@ type Config struct {
 	DBPassword string // Database password
 	DBName     string // Database name
 	DBSSLMode  string // Database SSL mode (disable/require/verify-ca/verify-full)
-	JWTSecret  string // Secret key untuk signing JWT tokens
-	JWTExpires string // JWT expiration duration (contoh: 168h = 7 hari)
+	JWTExpires string // Access token expiration duration (contoh: 15m)
 	Port       string // Port untuk aplikasi web server
 	NodeEnv    string // Environment mode (development/production)
 	CorsOrigin string // Allowed CORS origin (URL frontend)
 
+	// Refresh token configuration
+	JWTRefreshExpires string // Refresh token expiration duration (contoh: 720h = 30 hari)
+
+	// JWT signing keys (asimetris, disimpan di tabel signing_keys)
+	JWTSigningAlgorithm    string // RS256 atau EdDSA
+	JWTKeyRotationInterval string // Interval rotasi signing key (contoh: 720h)
+	JWTKeyVerifyGrace      string // Batas bawah lama key lama tetap dipakai untuk verifikasi; tetap dinaikkan ke masa berlaku token terlama (contoh: 24h)
+	JWTKeyEncryptionKey    string // Secret untuk mengenkripsi private key di tabel signing_keys (wajib di production)
+
 	// Mailjet email configuration
 	MailjetAPIKey    string // Mailjet API key
 	MailjetAPISecret string // Mailjet API secret
@@ -31,8 +40,54 @@ type Config struct {
 	MailjetHost      string // Mailjet SMTP host
 	MailSenderEmail  string // Email address untuk sender
 	MailSenderName   string // Nama sender yang tampil di email
+	MailDriver       string // Driver email: smtp, log atau file
+	MailFileDir      string // Folder output untuk driver file
+
+	AppURL string // Base URL aplikasi, dipakai untuk link di email
+
+	// Email verification
+	EmailVerificationExpires string // Masa berlaku link verifikasi (contoh: 24h)
+
+	// Password reset
+	PasswordResetExpires string // Masa berlaku token reset password (contoh: 1h)
+
+	// Two-factor authentication
+	TOTPIssuer      string // Nama issuer yang tampil di aplikasi authenticator
+	AdminRequire2FA string // "true" untuk mewajibkan 2FA pada akun admin hasil seeder
+
+	// Login brute-force protection
+	LoginMaxAttempts      string // Jumlah gagal login per akun sebelum dikunci sementara
+	LoginMaxAttemptsPerIP string // Jumlah gagal login per IP sebelum diblokir sementara
+	LoginAttemptWindow    string // Counter gagal login di-reset setelah tidak ada percobaan selama durasi ini
+	LoginLockoutBase      string // Durasi lockout pertama, berlipat dua untuk setiap kegagalan berikutnya
+	LoginLockoutMax       string // Batas maksimal durasi lockout
+
+	// Account deletion
+	AccountDeletionGracePeriod string // Jeda sebelum akun benar-benar dihapus, bisa dibatalkan dengan login (contoh: 720h)
+	AccountPurgeInterval       string // Interval job penghapusan akun (contoh: 1h)
+
+	// Username change
+	UsernameChangeCooldown    string // Jeda minimal antar penggantian username (contoh: 336h)
+	UsernameReservationPeriod string // Lama username lama tidak bisa dipakai user lain (contoh: 2160h)
+
+	// Personal data export
+	DataExportDir      string // Folder penyimpanan arsip ZIP hasil export
+	DataExportExpires  string // Masa berlaku arsip dan link download (contoh: 48h)
+	DataExportCooldown string // Jeda minimal antar permintaan export per user (contoh: 24h)
 
 	CookieDomain string // Domain untuk cookie
+
+	// OpenID Connect social login, key = nama provider (contoh: google)
+	OIDCProviders map[string]OIDCProviderConfig
+}
+
+// OIDCProviderConfig adalah konfigurasi satu provider OpenID Connect
+type OIDCProviderConfig struct {
+	Issuer       string   // Issuer URL, discovery diambil dari {issuer}/.well-known/openid-configuration
+	ClientID     string   // OAuth client ID
+	ClientSecret string   // OAuth client secret
+	RedirectURL  string   // Callback URL yang terdaftar di provider
+	Scopes       []string // Scope yang diminta (default: openid email profile)
 }
 
 // LoadConfig membaca konfigurasi dari file .env dan environment variables
@@ -54,12 +109,20 @@ func LoadConfig() *Config {
 		DBPassword: getEnv("DB_PASSWORD", ""),
 		DBName:     getEnv("DB_NAME", "post_db"),
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
-		JWTSecret:  getEnv("JWT_SECRET", "your_super_secret_jwt_key_post_app_2025"),
-		JWTExpires: getEnv("JWT_EXPIRES_IN", "168h"),
+		JWTExpires: getEnv("JWT_EXPIRES_IN", "15m"),
 		Port:       getEnv("PORT", "5000"),
 		NodeEnv:    getEnv("NODE_ENV", "development"),
 		CorsOrigin: getEnv("CORS_ORIGIN", "http://localhost:3000"),
 
+		// Refresh token configuration
+		JWTRefreshExpires: getEnv("JWT_REFRESH_EXPIRES_IN", "720h"),
+
+		// JWT signing keys
+		JWTSigningAlgorithm:    getEnv("JWT_SIGNING_ALG", "RS256"),
+		JWTKeyRotationInterval: getEnv("JWT_KEY_ROTATION_INTERVAL", "720h"),
+		JWTKeyVerifyGrace:      getEnv("JWT_KEY_VERIFY_GRACE", "24h"),
+		JWTKeyEncryptionKey:    getEnv("JWT_KEY_ENCRYPTION_KEY", ""),
+
 		// Mailjet configuration
 		MailjetAPIKey:    getEnv("MAILJET_API_KEY", ""),
 		MailjetAPISecret: getEnv("MAILJET_API_SECRET", ""),
@@ -67,9 +130,72 @@ func LoadConfig() *Config {
 		MailjetHost:      getEnv("MAILJET_HOST", "in-v3.mailjet.com"),
 		MailSenderEmail:  getEnv("MAIL_SENDER_EMAIL", "noreply@goevent.com"),
 		MailSenderName:   getEnv("MAIL_SENDER_NAME", "GoEvent App"),
+		MailDriver:       getEnv("MAIL_DRIVER", "log"),
+		MailFileDir:      getEnv("MAIL_FILE_DIR", "./storage/mails"),
+
+		AppURL: getEnv("APP_URL", "http://localhost:5000"),
+
+		// Email verification
+		EmailVerificationExpires: getEnv("EMAIL_VERIFICATION_EXPIRES_IN", "24h"),
+
+		// Password reset
+		PasswordResetExpires: getEnv("PASSWORD_RESET_EXPIRES_IN", "1h"),
+
+		// Two-factor authentication
+		TOTPIssuer:      getEnv("TOTP_ISSUER", "GO-SOSMED"),
+		AdminRequire2FA: getEnv("ADMIN_REQUIRE_2FA", "false"),
+
+		// Login brute-force protection
+		LoginMaxAttempts:      getEnv("LOGIN_MAX_ATTEMPTS", "5"),
+		LoginMaxAttemptsPerIP: getEnv("LOGIN_MAX_ATTEMPTS_PER_IP", "20"),
+		LoginAttemptWindow:    getEnv("LOGIN_ATTEMPT_WINDOW", "15m"),
+		LoginLockoutBase:      getEnv("LOGIN_LOCKOUT_BASE", "1m"),
+		LoginLockoutMax:       getEnv("LOGIN_LOCKOUT_MAX", "1h"),
+
+		// Account deletion
+		AccountDeletionGracePeriod: getEnv("ACCOUNT_DELETION_GRACE_PERIOD", "720h"),
+		AccountPurgeInterval:       getEnv("ACCOUNT_PURGE_INTERVAL", "1h"),
+
+		// Username change
+		UsernameChangeCooldown:    getEnv("USERNAME_CHANGE_COOLDOWN", "336h"),
+		UsernameReservationPeriod: getEnv("USERNAME_RESERVATION_PERIOD", "2160h"),
+
+		// Personal data export
+		DataExportDir:      getEnv("DATA_EXPORT_DIR", "./storage/exports"),
+		DataExportExpires:  getEnv("DATA_EXPORT_EXPIRES_IN", "48h"),
+		DataExportCooldown: getEnv("DATA_EXPORT_COOLDOWN", "24h"),
 
 		CookieDomain: getEnv("COOKIE_DOMAIN", ""),
+
+		OIDCProviders: loadOIDCProviders(),
+	}
+}
+
+// loadOIDCProviders membaca daftar provider dari OIDC_PROVIDERS (dipisah koma),
+// lalu konfigurasi tiap provider dari OIDC_<NAMA>_ISSUER, _CLIENT_ID, _CLIENT_SECRET,
+// _REDIRECT_URL dan _SCOPES. Provider tanpa issuer atau client ID diabaikan.
+func loadOIDCProviders() map[string]OIDCProviderConfig {
+	providers := make(map[string]OIDCProviderConfig)
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		cfg := OIDCProviderConfig{
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", "http://localhost:5000/api/auth/oidc/"+name+"/callback"),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		}
+		if cfg.Issuer == "" || cfg.ClientID == "" {
+			log.Printf("Warning: OIDC provider %q is missing issuer or client ID, skipped", name)
+			continue
+		}
+		providers[name] = cfg
 	}
+	return providers
 }
 
 // getEnv adalah helper function untuk membaca environment variable
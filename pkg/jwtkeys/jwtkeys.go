@@ -0,0 +1,543 @@
+// Package jwtkeys mengelola keyring untuk menandatangani JWT secara asimetris
+// (RS256 atau EdDSA). Setiap key punya kid, disimpan di tabel signing_keys dan
+// dirotasi secara berkala. Key baru dipublikasikan lewat JWKS lebih dulu dan baru
+// dipakai signing setelah cache JWKS di sisi verifier sempat diperbarui. Key lama
+// tetap dipakai untuk verifikasi sampai semua token yang ditandatanganinya
+// kadaluarsa. Private key disimpan terenkripsi (AES-GCM).
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-sosmed/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	// TypeAccessToken adalah header typ access token (RFC 9068), membedakannya dari
+	// JWT lain yang ditandatangani key yang sama (link verifikasi email, challenge 2FA)
+	TypeAccessToken = "at+jwt"
+
+	rsaKeySize = 2048
+	// jarak minimal reload dari database saat menemukan kid yang belum dikenal
+	// atau saat JWKS diminta
+	reloadInterval = 30 * time.Second
+
+	// JWKSMaxAge adalah lama response JWKS boleh di-cache (header Cache-Control)
+	JWKSMaxAge = 5 * time.Minute
+	// key baru sudah ada di JWKS selama ini sebelum dipakai signing, sehingga
+	// verifier yang masih memegang cache lama sempat mengambil JWKS terbaru
+	publishLead = 2 * JWKSMaxAge
+
+	// prefix private key yang tersimpan terenkripsi; tanpa prefix berarti PEM polos
+	encryptedPrefix = "enc:"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// SigningKey adalah satu key di keyring. Private key disimpan dalam PEM (PKCS#8),
+// dienkripsi dengan JWT_KEY_ENCRYPTION_KEY jika dikonfigurasi.
+type SigningKey struct {
+	ID          uint       `gorm:"primaryKey"`
+	KID         string     `gorm:"column:kid;size:64;not null;uniqueIndex"`
+	Algorithm   string     `gorm:"size:16;not null"`
+	PrivateKey  string     `gorm:"type:text;not null"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	ActivatesAt *time.Time // dipakai untuk signing mulai waktu ini, sebelumnya hanya dipublikasikan
+	RetiredAt   *time.Time // tidak dipakai untuk signing lagi sejak waktu ini
+	ExpiresAt   *time.Time `gorm:"index"` // tidak dipakai untuk verifikasi lagi sejak waktu ini
+}
+
+type loadedKey struct {
+	kid         string
+	algorithm   string
+	private     crypto.Signer
+	createdAt   time.Time
+	activatesAt *time.Time
+	retiredAt   *time.Time
+	expiresAt   *time.Time
+}
+
+// signs menentukan apakah key dipakai untuk signing pada waktu now
+func (key *loadedKey) signs(now time.Time) bool {
+	if key.activatesAt != nil && now.Before(*key.activatesAt) {
+		return false
+	}
+	return key.retiredAt == nil || now.Before(*key.retiredAt)
+}
+
+// Keyring menyimpan key aktif (untuk signing), key berikutnya yang sudah
+// dipublikasikan, dan key lama (untuk verifikasi)
+type Keyring struct {
+	db               *gorm.DB
+	algorithm        string
+	rotationInterval time.Duration
+	verifyGrace      time.Duration
+	aead             cipher.AEAD // nil jika private key disimpan tanpa enkripsi
+
+	mu         sync.RWMutex
+	keys       map[string]*loadedKey
+	lastLoaded time.Time
+}
+
+var defaultKeyring *Keyring
+
+// Init membuat keyring default dari konfigurasi, memastikan ada key aktif,
+// lalu menjalankan rotasi terjadwal di background
+func Init(db *gorm.DB, cfg *config.Config) error {
+	k, err := New(db, cfg)
+	if err != nil {
+		return err
+	}
+	defaultKeyring = k
+	go k.runRotation()
+	return nil
+}
+
+// Get mengembalikan keyring default yang dibuat oleh Init
+func Get() *Keyring {
+	return defaultKeyring
+}
+
+// New membuat keyring dan memuat key dari database (membuat key baru jika belum ada)
+func New(db *gorm.DB, cfg *config.Config) (*Keyring, error) {
+	algorithm := cfg.JWTSigningAlgorithm
+	if algorithm != AlgRS256 && algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("unsupported JWT signing algorithm %q", algorithm)
+	}
+	rotationInterval, err := time.ParseDuration(cfg.JWTKeyRotationInterval)
+	if err != nil || rotationInterval <= 0 {
+		rotationInterval = 30 * 24 * time.Hour
+	}
+	verifyGrace, err := time.ParseDuration(cfg.JWTKeyVerifyGrace)
+	if err != nil || verifyGrace <= 0 {
+		verifyGrace = 24 * time.Hour
+	}
+	// key lama harus bisa memverifikasi token terlama yang ditandatanganinya
+	if longest := longestTokenTTL(cfg); longest > verifyGrace {
+		verifyGrace = longest
+	}
+
+	k := &Keyring{
+		db:               db,
+		algorithm:        algorithm,
+		rotationInterval: rotationInterval,
+		verifyGrace:      verifyGrace,
+	}
+	if cfg.JWTKeyEncryptionKey != "" {
+		k.aead, err = newAEAD(cfg.JWTKeyEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+	} else if cfg.NodeEnv == "production" {
+		return nil, errors.New("JWT_KEY_ENCRYPTION_KEY is required in production")
+	} else {
+		log.Println("Warning: JWT_KEY_ENCRYPTION_KEY is not set, signing keys are stored unencrypted")
+	}
+	if err := k.RotateIfDue(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// longestTokenTTL adalah masa berlaku terlama dari JWT yang ditandatangani keyring:
+// access token, link verifikasi email dan link download export data
+func longestTokenTTL(cfg *config.Config) time.Duration {
+	var longest time.Duration
+	for _, value := range []string{cfg.JWTExpires, cfg.EmailVerificationExpires, cfg.DataExportExpires} {
+		if ttl, err := time.ParseDuration(value); err == nil && ttl > longest {
+			longest = ttl
+		}
+	}
+	return longest
+}
+
+// Sign menandatangani claims dengan key aktif; header kid dan typ ikut diisi
+func (k *Keyring) Sign(claims jwt.Claims, typ string) (string, error) {
+	k.mu.RLock()
+	active := k.active(time.Now())
+	k.mu.RUnlock()
+	if active == nil {
+		return "", errors.New("no active signing key")
+	}
+
+	t := jwt.NewWithClaims(signingMethod(active.algorithm), claims)
+	t.Header["kid"] = active.kid
+	if typ != "" {
+		t.Header["typ"] = typ
+	}
+	return t.SignedString(active.private)
+}
+
+// Keyfunc dipakai pada jwt.Parse untuk mencari public key berdasarkan kid
+func (k *Keyring) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, err := k.lookup(kid)
+	if err != nil {
+		return nil, err
+	}
+	if t.Method.Alg() != key.algorithm {
+		return nil, fmt.Errorf("signing method %s does not match key", t.Method.Alg())
+	}
+	return key.private.Public(), nil
+}
+
+// ValidMethods adalah algoritma yang diterima saat verifikasi
+func (k *Keyring) ValidMethods() []string {
+	return []string{AlgRS256, AlgEdDSA}
+}
+
+func (k *Keyring) lookup(kid string) (*loadedKey, error) {
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	stale := time.Since(k.lastLoaded) > reloadInterval
+	k.mu.RUnlock()
+
+	// kid belum dikenal: kemungkinan instance lain baru saja melakukan rotasi
+	if !ok && stale {
+		if err := k.load(); err != nil {
+			return nil, err
+		}
+		k.mu.RLock()
+		key, ok = k.keys[kid]
+		k.mu.RUnlock()
+	}
+	if !ok || (key.expiresAt != nil && time.Now().After(*key.expiresAt)) {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// active mengembalikan key terbaru yang dipakai signing pada waktu now.
+// Pemanggil harus memegang k.mu.
+func (k *Keyring) active(now time.Time) *loadedKey {
+	var active *loadedKey
+	for _, key := range k.keys {
+		if key.signs(now) && (active == nil || key.createdAt.After(active.createdAt)) {
+			active = key
+		}
+	}
+	return active
+}
+
+// latest mengembalikan key terbaru yang belum dipensiunkan, termasuk key yang
+// sudah dipublikasikan tapi belum dipakai signing. Pemanggil harus memegang k.mu.
+func (k *Keyring) latest() *loadedKey {
+	var latest *loadedKey
+	for _, key := range k.keys {
+		if key.retiredAt == nil && (latest == nil || key.createdAt.After(latest.createdAt)) {
+			latest = key
+		}
+	}
+	return latest
+}
+
+// RotateIfDue membuat key baru jika key terbaru sudah melewati interval rotasi
+func (k *Keyring) RotateIfDue() error {
+	if err := k.load(); err != nil {
+		return err
+	}
+	k.mu.RLock()
+	latest := k.latest()
+	k.mu.RUnlock()
+	if latest != nil && latest.algorithm == k.algorithm && time.Since(latest.createdAt) < k.rotationInterval {
+		return nil
+	}
+	return k.Rotate()
+}
+
+// Rotate membuat key baru dan menjadwalkan pergantian key aktif. Key baru
+// langsung masuk JWKS tapi baru dipakai signing setelah publishLead; pada saat
+// yang sama key sebelumnya pensiun dan masih bisa dipakai untuk verifikasi
+// selama verifyGrace. Jika belum ada key sama sekali, key baru langsung aktif.
+func (k *Keyring) Rotate() error {
+	newKey, err := generateKey(k.algorithm)
+	if err != nil {
+		return err
+	}
+	if newKey.PrivateKey, err = k.seal(newKey.KID, newKey.PrivateKey); err != nil {
+		return err
+	}
+
+	err = k.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// kunci baris key aktif agar beberapa instance tidak merotasi bersamaan
+		var current []SigningKey
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("retired_at IS NULL").
+			Find(&current).Error; err != nil {
+			return err
+		}
+		for _, key := range current {
+			if key.Algorithm == k.algorithm && now.Sub(key.CreatedAt) < k.rotationInterval {
+				// instance lain sudah merotasi
+				return nil
+			}
+		}
+
+		activatesAt := now
+		if len(current) > 0 {
+			activatesAt = now.Add(publishLead)
+		}
+		expiresAt := activatesAt.Add(k.verifyGrace)
+		if err := tx.Model(&SigningKey{}).
+			Where("retired_at IS NULL").
+			Updates(map[string]interface{}{"retired_at": activatesAt, "expires_at": expiresAt}).Error; err != nil {
+			return err
+		}
+		newKey.ActivatesAt = &activatesAt
+		return tx.Create(newKey).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rotate signing key: %w", err)
+	}
+	return k.load()
+}
+
+// load membaca semua key yang masih berlaku dari database
+func (k *Keyring) load() error {
+	var rows []SigningKey
+	if err := k.db.
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC").
+		Find(&rows).Error; err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	keys := make(map[string]*loadedKey, len(rows))
+	for _, row := range rows {
+		encoded, err := k.open(row.KID, row.PrivateKey)
+		if err != nil {
+			log.Printf("Warning: skipping undecryptable signing key %s: %v", row.KID, err)
+			continue
+		}
+		private, err := parsePrivateKey(encoded)
+		if err != nil {
+			log.Printf("Warning: skipping invalid signing key %s: %v", row.KID, err)
+			continue
+		}
+		if k.aead != nil && !strings.HasPrefix(row.PrivateKey, encryptedPrefix) {
+			k.encryptLegacy(row.ID, row.KID, encoded)
+		}
+		keys[row.KID] = &loadedKey{
+			kid:         row.KID,
+			algorithm:   row.Algorithm,
+			private:     private,
+			createdAt:   row.CreatedAt,
+			activatesAt: row.ActivatesAt,
+			retiredAt:   row.RetiredAt,
+			expiresAt:   row.ExpiresAt,
+		}
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.lastLoaded = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// runRotation mengecek jadwal rotasi dan membersihkan key kadaluarsa setiap jam.
+// Pergantian key aktif tidak menunggu ticker: Sign memilih key berdasarkan activates_at.
+func (k *Keyring) runRotation() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := k.RotateIfDue(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		if err := k.db.Where("expires_at IS NOT NULL AND expires_at < ?", time.Now()).
+			Delete(&SigningKey{}).Error; err != nil {
+			log.Printf("Warning: failed to delete expired signing keys: %v", err)
+		}
+	}
+}
+
+// JSONWebKey adalah public key dalam format JWK (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// encryptLegacy mengenkripsi private key yang tersimpan sebelum enkripsi diaktifkan
+func (k *Keyring) encryptLegacy(id uint, kid, encoded string) {
+	sealed, err := k.seal(kid, encoded)
+	if err == nil {
+		err = k.db.Model(&SigningKey{}).Where("id = ?", id).Update("private_key", sealed).Error
+	}
+	if err != nil {
+		log.Printf("Warning: failed to encrypt signing key %s: %v", kid, err)
+	}
+}
+
+// JWKS mengembalikan semua public key yang masih berlaku untuk verifikasi,
+// termasuk key berikutnya yang belum dipakai signing. Keyring dimuat ulang
+// jika sudah stale agar key hasil rotasi instance lain ikut dipublikasikan.
+func (k *Keyring) JWKS() JSONWebKeySet {
+	k.mu.RLock()
+	stale := time.Since(k.lastLoaded) > reloadInterval
+	k.mu.RUnlock()
+	if stale {
+		if err := k.load(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range k.keys {
+		if key.expiresAt != nil && time.Now().After(*key.expiresAt) {
+			continue
+		}
+		jwk := JSONWebKey{Kid: key.kid, Use: "sig", Alg: key.algorithm}
+		switch pub := key.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	// urutan stabil: key terbaru lebih dulu
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return k.keys[set.Keys[i].Kid].createdAt.After(k.keys[set.Keys[j].Kid].createdAt)
+	})
+	return set
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	if algorithm == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+func generateKey(algorithm string) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeySize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	kidBytes := make([]byte, 12)
+	if _, err := rand.Read(kidBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate key ID: %w", err)
+	}
+
+	return &SigningKey{
+		KID:        base64.RawURLEncoding.EncodeToString(kidBytes),
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	}, nil
+}
+
+// newAEAD membuat cipher AES-256-GCM dari secret JWT_KEY_ENCRYPTION_KEY
+func newAEAD(secret string) (cipher.AEAD, error) {
+	sum := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to init signing key encryption: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal mengenkripsi PEM private key; kid dipakai sebagai additional data agar
+// ciphertext tidak bisa dipindah ke baris key lain
+func (k *Keyring) seal(kid, encoded string) (string, error) {
+	if k.aead == nil {
+		return encoded, nil
+	}
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+	sealed := k.aead.Seal(nonce, nonce, []byte(encoded), []byte(kid))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open mengembalikan PEM private key; key lama yang tersimpan polos diterima apa adanya
+func (k *Keyring) open(kid, stored string) (string, error) {
+	if !strings.HasPrefix(stored, encryptedPrefix) {
+		return stored, nil
+	}
+	if k.aead == nil {
+		return "", errors.New("JWT_KEY_ENCRYPTION_KEY is not set")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, encryptedPrefix))
+	if err != nil || len(sealed) < k.aead.NonceSize() {
+		return "", errors.New("invalid ciphertext")
+	}
+	nonce, ciphertext := sealed[:k.aead.NonceSize()], sealed[k.aead.NonceSize():]
+	plain, err := k.aead.Open(nil, nonce, ciphertext, []byte(kid))
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func parsePrivateKey(encoded string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("invalid PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
@@ -0,0 +1,136 @@
+// Package mailer menyediakan abstraksi pengiriman email.
+// Driver yang tersedia: smtp (Mailjet), log (development) dan file (testing).
+package mailer
+
+import (
+	"fmt"
+	"go-sosmed/pkg/config"
+	"log"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Message adalah email yang akan dikirim
+type Message struct {
+	To      string
+	Subject string
+	Body    string // plain text
+}
+
+// Mailer adalah interface untuk semua driver pengirim email
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New membuat Mailer sesuai MAIL_DRIVER di konfigurasi
+func New(cfg *config.Config) Mailer {
+	switch cfg.MailDriver {
+	case "smtp":
+		return NewSMTPMailer(cfg)
+	case "file":
+		return NewFileMailer(cfg.MailFileDir, cfg.MailSenderEmail)
+	default:
+		return NewLogMailer(cfg.MailSenderEmail)
+	}
+}
+
+// =====================================
+// SMTP
+// =====================================
+
+type smtpMailer struct {
+	host      string
+	port      string
+	username  string
+	password  string
+	fromEmail string
+	fromName  string
+}
+
+// NewSMTPMailer mengirim email lewat SMTP Mailjet (API key & secret sebagai kredensial)
+func NewSMTPMailer(cfg *config.Config) Mailer {
+	return &smtpMailer{
+		host:      cfg.MailjetHost,
+		port:      cfg.MailjetPort,
+		username:  cfg.MailjetAPIKey,
+		password:  cfg.MailjetAPISecret,
+		fromEmail: cfg.MailSenderEmail,
+		fromName:  cfg.MailSenderName,
+	}
+}
+
+// Send implements Mailer.
+func (m *smtpMailer) Send(msg Message) error {
+	auth := smtp.PlainAuth("", m.username, m.password, m.host)
+	from := fmt.Sprintf("%s <%s>", m.fromName, m.fromEmail)
+	addr := m.host + ":" + m.port
+
+	if err := smtp.SendMail(addr, auth, m.fromEmail, []string{msg.To}, buildMessage(from, msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// =====================================
+// LOG
+// =====================================
+
+type logMailer struct {
+	fromEmail string
+}
+
+// NewLogMailer hanya menulis email ke log, cocok untuk development
+func NewLogMailer(fromEmail string) Mailer {
+	return &logMailer{fromEmail: fromEmail}
+}
+
+// Send implements Mailer.
+func (m *logMailer) Send(msg Message) error {
+	log.Printf("📧 [mail] from=%s to=%s subject=%q\n%s", m.fromEmail, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// =====================================
+// FILE
+// =====================================
+
+type fileMailer struct {
+	dir       string
+	fromEmail string
+}
+
+// NewFileMailer menyimpan setiap email sebagai file .eml di folder dir
+func NewFileMailer(dir, fromEmail string) Mailer {
+	return &fileMailer{dir: dir, fromEmail: fromEmail}
+}
+
+// Send implements Mailer.
+func (m *fileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create mail dir: %w", err)
+	}
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "", "\\", "").Replace(msg.To)
+	filename := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), recipient)
+	if err := os.WriteFile(filepath.Join(m.dir, filename), buildMessage(m.fromEmail, msg), 0644); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	return nil
+}
+
+// buildMessage menyusun email sederhana (header + body plain text)
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
@@ -1,34 +1,121 @@
 package middlewares
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
 	"strings"
+	"time"
 
 	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/jwtkeys"
+	"go-sosmed/pkg/rbac"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 type Claims struct {
-	ID   uint   `json:"id"`
-	Role string `json:"role"`
+	ID        uint   `json:"id"`
+	Role      string `json:"role"`
+	SessionID uint   `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+const (
+	AuthMethodSession             = "session"
+	AuthMethodPersonalAccessToken = "personal_access_token"
+
+	// PersonalAccessTokenPrefix membedakan personal access token dari JWT di header Authorization
+	PersonalAccessTokenPrefix = "sosmed_pat_"
+)
+
+// authUser adalah kolom user yang dibutuhkan untuk autentikasi
+type authUser struct {
+	ID                  uint
+	Role                string
+	TokensRevokedAt     *time.Time
+	EmailVerifiedAt     *time.Time
+	TwoFactorEnabled    bool
+	TwoFactorRequired   bool
+	SuspendedUntil      *time.Time
+	BannedAt            *time.Time
+	DeletionScheduledAt *time.Time
+}
+
+func findAuthUser(db *gorm.DB, userID uint) (*authUser, error) {
+	var u authUser
+	if err := db.Table("users").
+		Select("id, role, tokens_revoked_at, email_verified_at, two_factor_enabled, two_factor_required, suspended_until, banned_at, deletion_scheduled_at").
+		Where("id = ? AND purged_at IS NULL", userID).
+		Take(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// rejectSuspendedUser menolak request dari akun yang di-ban atau sedang di-suspend
+func rejectSuspendedUser(c *gin.Context, u *authUser) bool {
+	if u.BannedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "Akun Anda telah diblokir.",
+		})
+		c.Abort()
+		return true
+	}
+	if u.SuspendedUntil != nil && u.SuspendedUntil.After(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message":         "Akun Anda sedang ditangguhkan.",
+			"suspended_until": u.SuspendedUntil,
+		})
+		c.Abort()
+		return true
+	}
+	return false
+}
+
+// setAuthUser menaruh data user di context. Role diambil dari database (bukan dari
+// claim token) agar perubahan role langsung berlaku.
+func setAuthUser(c *gin.Context, u *authUser) {
+	c.Set("userID", u.ID)
+	c.Set("userRole", u.Role)
+	c.Set("emailVerified", u.EmailVerifiedAt != nil)
+	c.Set("twoFactorSetupRequired", u.TwoFactorRequired && !u.TwoFactorEnabled)
+}
+
+// extractToken mengambil token dari header Authorization atau cookie
+func extractToken(c *gin.Context) string {
+	tokenString := c.GetHeader("Authorization")
+	if tokenString != "" && strings.HasPrefix(tokenString, "Bearer ") {
+		return strings.TrimPrefix(tokenString, "Bearer ")
+	}
+	tokenString = c.GetString("token")
+	if tokenString == "" {
+		tokenString, _ = c.Cookie("token")
+	}
+	return tokenString
+}
+
+// OptionalAuthenticate dipakai pada route publik yang hasilnya bergantung pada
+// siapa yang melihat (misal post akun private). Tanpa token request diteruskan
+// sebagai anonim; token yang dikirim tetap divalidasi seperti Authenticate.
+func OptionalAuthenticate(cfg *config.Config) gin.HandlerFunc {
+	authenticate := Authenticate(cfg)
+	return func(c *gin.Context) {
+		if extractToken(c) == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
+
 func Authenticate(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		// Ambil token dari header Authorization atau cookie
-		tokenString := c.GetHeader("Authorization")
-		if tokenString != "" && strings.HasPrefix(tokenString, "Bearer ") {
-			tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-		} else {
-			tokenString = c.GetString("token")
-			if tokenString == "" {
-				tokenString, _ = c.Cookie("token")
-			}
-		}
+		tokenString := extractToken(c)
 
 		// Token tidak ada
 		if tokenString == "" {
@@ -39,12 +126,16 @@ func Authenticate(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		if strings.HasPrefix(tokenString, PersonalAccessTokenPrefix) {
+			authenticatePersonalAccessToken(c, tokenString)
+			return
+		}
+
 		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
-			return []byte(cfg.JWTSecret), nil
-		})
+		keyring := jwtkeys.Get()
+		token, err := jwt.ParseWithClaims(tokenString, claims, keyring.Keyfunc, jwt.WithValidMethods(keyring.ValidMethods()))
 
-		if err != nil || !token.Valid {
+		if err != nil || !token.Valid || token.Header["typ"] != jwtkeys.TypeAccessToken {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"message": "Token tidak valid atau kadaluarsa.",
 			})
@@ -55,27 +146,140 @@ func Authenticate(cfg *config.Config) gin.HandlerFunc {
 		// Validasi user ada di database
 		db := config.GetDB()
 
-		var count int64
-		if err := db.Table("users").Where("id = ?", claims.ID).Count(&count).Error; err != nil || count == 0 {
+		authUser, err := findAuthUser(db, claims.ID)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"message": "User tidak ditemukan.",
 			})
 			c.Abort()
 			return
 		}
+		if rejectSuspendedUser(c, authUser) {
+			return
+		}
+
+		// Token yang terbit sebelum "logout dari semua perangkat" tidak berlaku lagi
+		if authUser.TokensRevokedAt != nil && claims.IssuedAt != nil &&
+			claims.IssuedAt.Time.Before(*authUser.TokensRevokedAt) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": "Token sudah dicabut.",
+			})
+			c.Abort()
+			return
+		}
+
+		// Cek denylist token (logout)
+		tokenID := claims.RegisteredClaims.ID
+		if tokenID != "" {
+			var revoked int64
+			if err := db.Table("revoked_tokens").Where("jti = ?", tokenID).Count(&revoked).Error; err != nil || revoked > 0 {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"message": "Token sudah dicabut.",
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		// Cek session masih aktif (session bisa dicabut dari daftar perangkat)
+		if claims.SessionID != 0 {
+			var session struct {
+				RevokedAt  *time.Time
+				LastSeenAt time.Time
+			}
+			if err := db.Table("sessions").
+				Select("revoked_at, last_seen_at").
+				Where("id = ? AND user_id = ?", claims.SessionID, claims.ID).
+				Take(&session).Error; err != nil || session.RevokedAt != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"message": "Session sudah berakhir.",
+				})
+				c.Abort()
+				return
+			}
+
+			// Update last seen paling sering sekali per menit
+			if time.Since(session.LastSeenAt) > time.Minute {
+				db.Table("sessions").Where("id = ?", claims.SessionID).Update("last_seen_at", time.Now())
+			}
+		}
 
 		// Taruh user data di context
-		c.Set("userID", claims.ID)
-		c.Set("userRole", claims.Role)
+		setAuthUser(c, authUser)
+		c.Set("authMethod", AuthMethodSession)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("tokenID", tokenID)
+		if claims.ExpiresAt != nil {
+			c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+		}
 
 		c.Next()
 	}
 }
 
-func Authorize(roles ...string) gin.HandlerFunc {
+// authenticatePersonalAccessToken memvalidasi personal access token (disimpan sebagai
+// hash SHA-256) lalu menaruh user dan scope token di context
+func authenticatePersonalAccessToken(c *gin.Context, raw string) {
+	db := config.GetDB()
+
+	sum := sha256.Sum256([]byte(raw))
+	var pat struct {
+		ID         uint
+		UserID     uint
+		Scopes     string
+		LastUsedAt *time.Time
+	}
+	if err := db.Table("personal_access_tokens").
+		Select("id, user_id, scopes, last_used_at").
+		Where("token_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", hex.EncodeToString(sum[:]), time.Now()).
+		Take(&pat).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"message": "Token tidak valid atau kadaluarsa.",
+		})
+		c.Abort()
+		return
+	}
+
+	authUser, err := findAuthUser(db, pat.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"message": "User tidak ditemukan.",
+		})
+		c.Abort()
+		return
+	}
+	if rejectSuspendedUser(c, authUser) {
+		return
+	}
+	// Akun yang dijadwalkan untuk dihapus hanya bisa dipulihkan lewat login,
+	// personal access token tidak dipakai lagi sampai penghapusan dibatalkan
+	if authUser.DeletionScheduledAt != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"message": "Akun sedang dijadwalkan untuk dihapus.",
+		})
+		c.Abort()
+		return
+	}
+
+	// Update last used paling sering sekali per menit
+	if pat.LastUsedAt == nil || time.Since(*pat.LastUsedAt) > time.Minute {
+		db.Table("personal_access_tokens").Where("id = ?", pat.ID).Update("last_used_at", time.Now())
+	}
+
+	setAuthUser(c, authUser)
+	c.Set("authMethod", AuthMethodPersonalAccessToken)
+	c.Set("personalAccessTokenID", pat.ID)
+	c.Set("tokenScopes", strings.Fields(pat.Scopes))
+
+	c.Next()
+}
+
+// RequirePermission membatasi route untuk role yang memiliki permission tertentu.
+// Harus dipasang setelah Authenticate.
+func RequirePermission(permission rbac.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
-		roleValue, exists := c.Get("userRole")
+		userRole, exists := c.Get("userRole")
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"message": "User belum terautentikasi.",
@@ -84,25 +288,45 @@ func Authorize(roles ...string) gin.HandlerFunc {
 			return
 		}
 
-		userRole := roleValue.(string)
-
-		// Jika tidak ada batasan role, semua user boleh
-		if len(roles) == 0 {
-			c.Next()
+		role, _ := userRole.(string)
+		if !rbac.HasPermission(role, permission) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "Akses ditolak. Anda tidak memiliki izin yang sesuai.",
+			})
+			c.Abort()
 			return
 		}
 
-		// Cocokkan role
-		for _, r := range roles {
-			if r == userRole {
-				c.Next()
-				return
-			}
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail membatasi route hanya untuk user yang emailnya sudah terverifikasi.
+// Harus dipasang setelah Authenticate.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("emailVerified") {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "Email belum diverifikasi. Silakan cek email Anda.",
+			})
+			c.Abort()
+			return
 		}
+		c.Next()
+	}
+}
 
-		c.JSON(http.StatusForbidden, gin.H{
-			"message": "Akses ditolak. Anda tidak memiliki izin yang sesuai.",
-		})
-		c.Abort()
+// RequireTwoFactor menolak akses jika akun diwajibkan memakai 2FA tapi belum mengaktifkannya.
+// Dipasang pada route sensitif (misalnya route admin) setelah Authenticate.
+func RequireTwoFactor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("twoFactorSetupRequired") {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "Akun ini wajib mengaktifkan two-factor authentication.",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
 	}
 }
@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scope untuk personal access token. Login biasa (JWT) selalu punya akses penuh,
+// scope hanya membatasi request yang memakai personal access token.
+const (
+	ScopeUsersRead     = "users:read"
+	ScopeProfileWrite  = "profile:write"
+	ScopePostsRead     = "posts:read"
+	ScopePostsWrite    = "posts:write"
+	ScopeCommentsRead  = "comments:read"
+	ScopeCommentsWrite = "comments:write"
+	ScopeLikesWrite    = "likes:write"
+	ScopeFollowsWrite  = "follows:write"
+	ScopeBlocksWrite   = "blocks:write"
+	ScopeMutesWrite    = "mutes:write"
+	ScopeReportsRead   = "reports:read"
+	ScopeReportsWrite  = "reports:write"
+)
+
+// PersonalAccessTokenScopes adalah daftar scope yang boleh diminta saat membuat token
+var PersonalAccessTokenScopes = []string{
+	ScopeUsersRead,
+	ScopeProfileWrite,
+	ScopePostsRead,
+	ScopePostsWrite,
+	ScopeCommentsRead,
+	ScopeCommentsWrite,
+	ScopeLikesWrite,
+	ScopeFollowsWrite,
+	ScopeBlocksWrite,
+	ScopeMutesWrite,
+	ScopeReportsRead,
+	ScopeReportsWrite,
+}
+
+// IsValidScope mengecek apakah scope dikenal
+func IsValidScope(scope string) bool {
+	for _, s := range PersonalAccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScope mewajibkan scope tertentu jika request memakai personal access token.
+// Harus dipasang setelah Authenticate.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") != AuthMethodPersonalAccessToken {
+			c.Next()
+			return
+		}
+		for _, s := range c.GetStringSlice("tokenScopes") {
+			if s == scope {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "Token tidak memiliki scope " + scope + ".",
+		})
+		c.Abort()
+	}
+}
+
+// RequireSessionAuth menolak personal access token pada route pengelolaan akun
+// (password, 2FA, session, token) yang hanya boleh diakses dari login biasa.
+func RequireSessionAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") == AuthMethodPersonalAccessToken {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "Endpoint ini tidak bisa diakses dengan personal access token.",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -19,6 +19,9 @@ type UploadConfig struct {
 	UploadDir     string // path di server (./uploads/posts)
 	PublicPath    string // path untuk frontend (/uploads/posts)
 	FileFieldName string
+	// key context untuk path file yang di-upload (default "uploadedFile"), diisi
+	// jika beberapa upload dipasang pada route yang sama
+	ContextKey string
 }
 
 // DefaultUploadConfig memberikan konfigurasi default untuk upload
@@ -44,6 +47,11 @@ func UploadSingleFile(config *UploadConfig) gin.HandlerFunc {
 			config = &defaultConfig
 		}
 
+		key := config.ContextKey
+		if key == "" {
+			key = "uploadedFile"
+		}
+
 		// Pastikan folder uploads ada
 		if err := ensureUploadDir(config.UploadDir); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -58,7 +66,7 @@ func UploadSingleFile(config *UploadConfig) gin.HandlerFunc {
 		if err := c.Request.ParseMultipartForm(config.MaxFileSize); err != nil {
 			// Jika error parsing, cek apakah memang tidak ada file
 			// Jika tidak ada file, lanjutkan tanpa upload (opsional)
-			c.Set("uploadedFile", "")
+			c.Set(key, "")
 			c.Next()
 			return
 		}
@@ -68,7 +76,7 @@ func UploadSingleFile(config *UploadConfig) gin.HandlerFunc {
 		if err != nil {
 			// Jika file tidak ada, lanjutkan tanpa upload (opsional)
 			// Set context dengan empty string
-			c.Set("uploadedFile", "")
+			c.Set(key, "")
 			c.Next()
 			return
 		}
@@ -124,9 +132,9 @@ func UploadSingleFile(config *UploadConfig) gin.HandlerFunc {
 			filepath.Join(config.PublicPath, filename),
 		)
 
-		c.Set("uploadedFile", publicURL)
-		c.Set("uploadedFileName", filename)
-		c.Set("uploadedFilePath", filePath)
+		c.Set(key, publicURL)
+		c.Set(key+"Name", filename)
+		c.Set(key+"Path", filePath)
 
 		c.Next()
 	}
@@ -142,6 +150,19 @@ func UploadAvatar() gin.HandlerFunc {
 	})
 }
 
+// UploadBanner menyimpan banner profil; dipasang bersama UploadAvatar sehingga
+// path-nya disimpan di key context tersendiri
+func UploadBanner() gin.HandlerFunc {
+	return UploadSingleFile(&UploadConfig{
+		MaxFileSize:   5 * 1024 * 1024,
+		AllowedTypes:  []string{".jpg", ".jpeg", ".png", ".webp"},
+		UploadDir:     "./uploads/banners",
+		PublicPath:    "/uploads/banners",
+		FileFieldName: "banner",
+		ContextKey:    "uploadedBanner",
+	})
+}
+
 func UploadPostImage() gin.HandlerFunc {
 	return UploadSingleFile(&UploadConfig{
 		MaxFileSize:   5 * 1024 * 1024,
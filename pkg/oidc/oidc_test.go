@@ -0,0 +1,139 @@
+package oidc_test
+
+import (
+	"context"
+	"go-sosmed/pkg/config"
+	"go-sosmed/pkg/oidc"
+	"go-sosmed/pkg/oidc/oidctest"
+	"strings"
+	"testing"
+)
+
+const clientID = "go-sosmed-test"
+
+func newProvider(t *testing.T) (*oidc.Provider, *oidctest.Server) {
+	t.Helper()
+	server := oidctest.NewServer(clientID)
+	t.Cleanup(server.Close)
+	providers := oidc.NewProviders(map[string]config.OIDCProviderConfig{
+		"mock": {
+			Issuer:      server.URL,
+			ClientID:    clientID,
+			RedirectURL: "http://localhost/callback",
+			Scopes:      []string{"openid", "email", "profile"},
+		},
+	})
+	return providers["mock"], server
+}
+
+func TestExchange(t *testing.T) {
+	const (
+		verifier = "verifier-0123456789-0123456789-0123456789"
+		nonce    = "nonce-123"
+	)
+	baseClaims := map[string]interface{}{
+		"sub":            "subject-1",
+		"email":          "alice@example.com",
+		"email_verified": true,
+	}
+
+	tests := []struct {
+		name     string
+		grant    oidctest.Grant
+		verifier string
+		nonce    string
+		wantErr  string
+	}{
+		{name: "valid", grant: oidctest.Grant{Claims: baseClaims}, verifier: verifier, nonce: nonce},
+		{name: "wrong code verifier", grant: oidctest.Grant{Claims: baseClaims}, verifier: "another-verifier-0123456789-0123456789", nonce: nonce, wantErr: "PKCE"},
+		{name: "wrong issuer", grant: oidctest.Grant{Issuer: "https://evil.example.com", Claims: baseClaims}, verifier: verifier, nonce: nonce, wantErr: "issuer"},
+		{name: "wrong audience", grant: oidctest.Grant{Audience: "another-client", Claims: baseClaims}, verifier: verifier, nonce: nonce, wantErr: "audience"},
+		{name: "nonce mismatch", grant: oidctest.Grant{Claims: baseClaims}, verifier: verifier, nonce: "replayed-nonce", wantErr: "nonce"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, server := newProvider(t)
+			ctx := context.Background()
+
+			authURL, err := provider.AuthCodeURL(ctx, "state", nonce, verifier)
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+			code, err := server.Authorize(authURL, tt.grant)
+			if err != nil {
+				t.Fatalf("Authorize: %v", err)
+			}
+
+			claims, err := provider.Exchange(ctx, code, tt.verifier, tt.nonce)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Exchange error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if claims.Subject != "subject-1" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+				t.Fatalf("unexpected claims %+v", claims)
+			}
+		})
+	}
+}
+
+func TestExchangeCodeIsSingleUse(t *testing.T) {
+	provider, server := newProvider(t)
+	ctx := context.Background()
+	const verifier = "verifier-0123456789-0123456789-0123456789"
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, err := server.Authorize(authURL, oidctest.Grant{Claims: map[string]interface{}{"sub": "subject-1"}})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if _, err := provider.Exchange(ctx, code, verifier, "nonce"); err != nil {
+		t.Fatalf("first Exchange: %v", err)
+	}
+	if _, err := provider.Exchange(ctx, code, verifier, "nonce"); err == nil {
+		t.Fatal("second Exchange with the same code succeeded")
+	}
+}
+
+func TestExchangeEmailVerifiedAsString(t *testing.T) {
+	for _, tt := range []struct {
+		value interface{}
+		want  bool
+	}{
+		{"true", true},
+		{"false", false},
+		{false, false},
+	} {
+		provider, server := newProvider(t)
+		ctx := context.Background()
+		const verifier = "verifier-0123456789-0123456789-0123456789"
+
+		authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", verifier)
+		if err != nil {
+			t.Fatalf("AuthCodeURL: %v", err)
+		}
+		code, err := server.Authorize(authURL, oidctest.Grant{Claims: map[string]interface{}{
+			"sub":            "subject-1",
+			"email":          "alice@example.com",
+			"email_verified": tt.value,
+		}})
+		if err != nil {
+			t.Fatalf("Authorize: %v", err)
+		}
+		claims, err := provider.Exchange(ctx, code, verifier, "nonce")
+		if err != nil {
+			t.Fatalf("Exchange: %v", err)
+		}
+		if claims.EmailVerified != tt.want {
+			t.Errorf("email_verified %v: got %v, want %v", tt.value, claims.EmailVerified, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package oidctest adalah mock provider OpenID Connect lokal untuk test.
+// Server melayani discovery document, JWKS dan token endpoint (authorization
+// code + PKCE S256), dan menandatangani ID token dengan key RSA sementara.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "oidctest"
+
+// Grant adalah hasil satu authorization request yang bisa ditukar di token endpoint
+type Grant struct {
+	Challenge string // code_challenge dari authorization URL
+	Nonce     string // nonce yang dimasukkan ke ID token
+	Issuer    string // kosong berarti URL server
+	Audience  string // kosong berarti ClientID
+	// claim tambahan ID token, misalnya sub, email, email_verified, preferred_username
+	Claims map[string]interface{}
+}
+
+// Server adalah mock provider OIDC di atas httptest.Server
+type Server struct {
+	*httptest.Server
+	ClientID string
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]Grant
+	next  int
+}
+
+// NewServer menjalankan mock provider untuk clientID. Panggil Close setelah selesai.
+func NewServer(clientID string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: failed to generate key: %v", err))
+	}
+	s := &Server{ClientID: clientID, key: key, codes: make(map[string]Grant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/jwks", s.jwks)
+	mux.HandleFunc("/token", s.token)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Authorize mensimulasikan user yang menyetujui login di halaman provider:
+// code_challenge dan nonce diambil dari authURL (kecuali sudah diisi di grant)
+// dan authorization code untuk token endpoint dikembalikan.
+func (s *Server) Authorize(authURL string, grant Grant) (string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	if query.Get("client_id") != s.ClientID {
+		return "", fmt.Errorf("unexpected client_id %q", query.Get("client_id"))
+	}
+	if query.Get("code_challenge_method") != "S256" {
+		return "", fmt.Errorf("unexpected code_challenge_method %q", query.Get("code_challenge_method"))
+	}
+	if grant.Challenge == "" {
+		grant.Challenge = query.Get("code_challenge")
+	}
+	if grant.Nonce == "" {
+		grant.Nonce = query.Get("nonce")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	code := fmt.Sprintf("code-%d", s.next)
+	s.codes[code] = grant
+	return code, nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("client_id") != s.ClientID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	// code hanya bisa dipakai sekali
+	s.mu.Lock()
+	grant, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != grant.Challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "PKCE verification failed",
+		})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   s.URL,
+		"aud":   s.ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": grant.Nonce,
+	}
+	if grant.Issuer != "" {
+		claims["iss"] = grant.Issuer
+	}
+	if grant.Audience != "" {
+		claims["aud"] = grant.Audience
+	}
+	for k, v := range grant.Claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(s.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access-" + r.PostForm.Get("code"),
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,185 @@
+// Package pagination menyediakan cursor pagination (keyset) untuk endpoint list.
+// Cursor bersifat opaque bagi client: berisi posisi (created_at, id) baris terakhir
+// di halaman sebelumnya, di-encode sebagai base64url JSON.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor parameter")
+	ErrInvalidLimit  = errors.New("invalid limit parameter")
+)
+
+// Cursor menandai baris terakhir yang sudah dikirim ke client.
+// CreatedAt kosong untuk list yang hanya diurutkan berdasarkan id.
+// Score hanya dipakai list yang diurutkan berdasarkan skor (feed For You).
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+	Score     float64
+}
+
+// cursorJSON adalah bentuk cursor di dalam string opaque
+type cursorJSON struct {
+	T *time.Time `json:"t,omitempty"`
+	I uint       `json:"i"`
+	S float64    `json:"s,omitempty"`
+}
+
+// Encode mengubah cursor menjadi string opaque untuk response
+func (c Cursor) Encode() string {
+	raw := cursorJSON{I: c.ID, S: c.Score}
+	if !c.CreatedAt.IsZero() {
+		raw.T = &c.CreatedAt
+	}
+	b, _ := json.Marshal(raw)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode membaca cursor dari query string
+func Decode(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var raw cursorJSON
+	if err := json.Unmarshal(b, &raw); err != nil || raw.I == 0 {
+		return nil, ErrInvalidCursor
+	}
+	c := &Cursor{ID: raw.I, Score: raw.S}
+	if raw.T != nil {
+		c.CreatedAt = *raw.T
+	}
+	return c, nil
+}
+
+// Page adalah permintaan satu halaman: jumlah item dan posisi awal (nil = halaman pertama)
+type Page struct {
+	Limit int
+	After *Cursor
+}
+
+// New membuat Page dari nilai limit dan cursor mentah
+func New(limit int, cursor string) (Page, error) {
+	if limit <= 0 {
+		return Page{}, ErrInvalidLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	page := Page{Limit: limit}
+	if cursor != "" {
+		after, err := Decode(cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		page.After = after
+	}
+	return page, nil
+}
+
+// FromQuery membaca parameter ?limit= dan ?cursor= dari request
+func FromQuery(c *gin.Context) (Page, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DefaultLimit)))
+	if err != nil {
+		return Page{}, ErrInvalidLimit
+	}
+	return New(limit, c.Query("cursor"))
+}
+
+// First mengembalikan halaman pertama dengan limit tertentu
+func First(limit int) Page {
+	return Page{Limit: limit}
+}
+
+// Newest mengurutkan dari yang terbaru (created_at DESC, id DESC) dan
+// melanjutkan setelah cursor. Mengambil limit+1 baris agar Trim bisa tahu
+// apakah masih ada halaman berikutnya.
+func (p Page) Newest(createdAtColumn, idColumn string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if p.After != nil {
+			db = db.Where(
+				"("+createdAtColumn+" < ? OR ("+createdAtColumn+" = ? AND "+idColumn+" < ?))",
+				p.After.CreatedAt, p.After.CreatedAt, p.After.ID,
+			)
+		}
+		return db.Order(createdAtColumn + " DESC").Order(idColumn + " DESC").Limit(p.Limit + 1)
+	}
+}
+
+// Oldest sama seperti Newest tetapi urut dari yang terlama
+func (p Page) Oldest(createdAtColumn, idColumn string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if p.After != nil {
+			db = db.Where(
+				"("+createdAtColumn+" > ? OR ("+createdAtColumn+" = ? AND "+idColumn+" > ?))",
+				p.After.CreatedAt, p.After.CreatedAt, p.After.ID,
+			)
+		}
+		return db.Order(createdAtColumn + " ASC").Order(idColumn + " ASC").Limit(p.Limit + 1)
+	}
+}
+
+// ByID mengurutkan berdasarkan id saja (terbesar dulu), untuk tabel tanpa created_at
+func (p Page) ByID(idColumn string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if p.After != nil {
+			db = db.Where(idColumn+" < ?", p.After.ID)
+		}
+		return db.Order(idColumn + " DESC").Limit(p.Limit + 1)
+	}
+}
+
+// Split membuang baris tambahan hasil query limit+1 dan mengembalikan cursor
+// halaman berikutnya, atau nil jika ini halaman terakhir.
+func Split[T any](items []T, p Page, cursorOf func(T) Cursor) ([]T, *Cursor) {
+	if len(items) <= p.Limit {
+		return items, nil
+	}
+	items = items[:p.Limit]
+	next := cursorOf(items[len(items)-1])
+	return items, &next
+}
+
+// Trim sama seperti Split tetapi mengembalikan next cursor yang sudah di-encode
+// untuk response. String kosong berarti halaman terakhir.
+func Trim[T any](items []T, p Page, cursorOf func(T) Cursor) ([]T, string) {
+	items, next := Split(items, p, cursorOf)
+	if next == nil {
+		return items, ""
+	}
+	return items, next.Encode()
+}
+
+// Collect membaca semua halaman sampai habis, per batch sebesar limit.
+// Dipakai untuk proses internal seperti export data, bukan untuk response API.
+func Collect[T any](limit int, fetch func(Page) ([]T, error), cursorOf func(T) Cursor) ([]T, error) {
+	var all []T
+	page := First(limit)
+	for {
+		items, err := fetch(page)
+		if err != nil {
+			return nil, err
+		}
+		items, next := Split(items, page, cursorOf)
+		all = append(all, items...)
+		if next == nil {
+			return all, nil
+		}
+		page.After = next
+	}
+}
@@ -0,0 +1,74 @@
+// Package rbac berisi daftar role, permission dan mapping role ke permission.
+// Route memeriksa permission (bukan nama role) sehingga role baru cukup
+// ditambahkan di sini tanpa mengubah route.
+package rbac
+
+type Permission = string
+
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleUser      = "user"
+)
+
+const (
+	PermReportReview     Permission = "report.review"      // melihat dan memproses laporan
+	PermPostDeleteAny    Permission = "post.delete.any"    // menghapus post milik user lain
+	PermCommentDeleteAny Permission = "comment.delete.any" // menghapus komentar milik user lain
+	PermUserSuspend      Permission = "user.suspend"       // menangguhkan akun sementara
+	PermUserBan          Permission = "user.ban"           // memblokir akun permanen dan mencabut blokirnya
+	PermUserUnlock       Permission = "user.unlock"        // membuka lockout login
+	PermUserAssignRole   Permission = "user.role.assign"   // mengubah role user
+	PermUserVerify       Permission = "user.verify"        // meninjau permintaan verifikasi akun
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleAdmin: {
+		PermReportReview,
+		PermPostDeleteAny,
+		PermCommentDeleteAny,
+		PermUserSuspend,
+		PermUserBan,
+		PermUserUnlock,
+		PermUserAssignRole,
+		PermUserVerify,
+	},
+	RoleModerator: {
+		PermReportReview,
+		PermPostDeleteAny,
+		PermCommentDeleteAny,
+		PermUserSuspend,
+	},
+	RoleUser: {},
+}
+
+// Roles mengembalikan semua role yang dikenal
+func Roles() []string {
+	return []string{RoleAdmin, RoleModerator, RoleUser}
+}
+
+// IsValidRole mengecek apakah role dikenal
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission mengecek apakah role memiliki permission tertentu
+func HasPermission(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions mengembalikan daftar permission milik role
+func Permissions(role string) []Permission {
+	return append([]Permission{}, rolePermissions[role]...)
+}
+
+// IsPrivileged mengecek apakah role punya permission apa pun (admin, moderator)
+func IsPrivileged(role string) bool {
+	return len(rolePermissions[role]) > 0
+}
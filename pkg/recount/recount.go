@@ -0,0 +1,38 @@
+// Package recount menjalankan recount counter (like, komentar, follow) untuk
+// seluruh baris sebuah tabel, dipakai cmd/reconcile dan migrasi di cmd/main.
+package recount
+
+import "gorm.io/gorm"
+
+// jumlah baris yang dihitung ulang per UPDATE, agar lock tidak menahan tabel terlalu lama
+const batchSize = 1000
+
+// Func menghitung ulang counter baris dengan id di ids dan mengembalikan
+// jumlah baris yang nilainya berubah, misal post.RecountCounters.
+type Func func(db *gorm.DB, ids []uint) (int64, error)
+
+// All menjalankan recount untuk seluruh baris table per batch id
+func All(db *gorm.DB, table string, recount Func) (int64, error) {
+	var total int64
+	var lastID uint
+	for {
+		var ids []uint
+		err := db.Table(table).
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(batchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		changed, err := recount(db, ids)
+		if err != nil {
+			return total, err
+		}
+		total += changed
+		lastID = ids[len(ids)-1]
+	}
+}
@@ -11,6 +11,17 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data"`
 }
 
+// PaginatedResponse is a success response for list endpoints for Swagger docs.
+// next_cursor is empty on the last page.
+// @Description Paginated response
+// @example {"success":true,"message":"string","data":[],"next_cursor":"string"}
+type PaginatedResponse struct {
+	Success    bool        `json:"success" example:"true"`
+	Message    string      `json:"message" example:"Operation successful"`
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor" example:"eyJ0IjoiMjAyNi0wMS0wMVQwMDowMDowMFoiLCJpIjo0Mn0"`
+}
+
 // ErrorResponse is a standard error response for Swagger docs
 // @Description Error response
 // @example {"success":false,"message":"error message"}
@@ -27,6 +38,16 @@ func Success(c *gin.Context, status int, message string, data interface{}) {
 	})
 }
 
+// Paginated sends a list page; pass nextCursor back as ?cursor= to get the next page
+func Paginated(c *gin.Context, status int, message string, data interface{}, nextCursor string) {
+	c.JSON(status, gin.H{
+		"success":     true,
+		"message":     message,
+		"data":        data,
+		"next_cursor": nextCursor,
+	})
+}
+
 func Error(c *gin.Context, status int, message string) {
 	c.JSON(status, gin.H{
 		"success": false,
@@ -66,6 +66,13 @@ func getAllUsedFiles(db *gorm.DB) ([]string, error) {
 		return nil, err
 	}
 
+	var banners []string
+	if err := db.Model(&user.User{}).
+		Where("banner != ''").
+		Pluck("banner", &banners).Error; err != nil {
+		return nil, err
+	}
+
 	var postImages []string
 	if err := db.Model(&post.Post{}).
 		Where("image != ''").
@@ -74,6 +81,7 @@ func getAllUsedFiles(db *gorm.DB) ([]string, error) {
 	}
 
 	files = append(files, avatars...)
+	files = append(files, banners...)
 	files = append(files, postImages...)
 
 	return files, nil
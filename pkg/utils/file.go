@@ -27,6 +27,15 @@ func normalizeUploadPath(input string) string {
 	return input
 }
 
+// LocalPath mengubah path publik / URL upload menjadi path lokal di server
+// Parameter:
+//   - filePath: path publik (misal: /uploads/posts/file.jpg)
+//
+// Returns: path lokal (misal: uploads/posts/file.jpg)
+func LocalPath(filePath string) string {
+	return normalizeUploadPath(filePath)
+}
+
 // DeleteFile menghapus file dari sistem jika ada
 // Parameter:
 //   - filePath: path relatif atau absolut dari file yang akan dihapus